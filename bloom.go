@@ -0,0 +1,348 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sync"
+)
+
+// bloomSet is an approximate Set backed by a Bloom filter: a fixed-size bit
+// array plus k hash functions. It's a good fit for very large, append-mostly
+// sets used purely for membership tests, where the cost of storing every
+// element (as the map-backed implementations do) is prohibitive.
+//
+// Approximation comes with sharp edges that every other Set implementation
+// in this package doesn't have: Contains can return a false positive (never
+// a false negative), Cardinality is an estimate, and elements are never
+// actually stored. Methods that would need to enumerate elements to give a
+// real answer (Each, Iter, Iterator, ToSlice, Pop) behave as if the set
+// were empty rather than panicking, since generic code that ranges over
+// any Set shouldn't crash just because it was handed a bloomSet; methods
+// where an empty-set answer would be actively misleading (Difference,
+// PowerSet, CartesianProduct and friends) panic instead.
+type bloomSet struct {
+	mu   sync.RWMutex
+	bits []uint64
+	m    uint64 // number of bits
+	k    uint64 // number of hash functions
+	n    uint64 // number of successful (bloom-reported-new) Adds
+}
+
+// NewBloomSet creates a Set backed by a Bloom filter sized for expectedN
+// elements at the given falsePositiveRate (e.g. 0.01 for 1%). The filter
+// allocates m = -expectedN*ln(p)/(ln2)^2 bits and k = (m/expectedN)*ln2
+// hash functions, per the standard Bloom filter sizing formulas.
+func NewBloomSet(expectedN uint, falsePositiveRate float64) Set {
+	n := float64(expectedN)
+	if n < 1 {
+		n = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	m := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round((float64(m) / n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	words := (m + 63) / 64
+	return &bloomSet{
+		bits: make([]uint64, words),
+		m:    words * 64,
+		k:    k,
+	}
+}
+
+// hashes returns the two independent 64-bit hashes of i that seed the
+// double-hashing scheme h_i(x) = h1(x) + i*h2(x) mod m.
+func hashes(i interface{}) (uint64, uint64) {
+	data := []byte(fmt.Sprint(i))
+
+	h1 := fnv.New64a()
+	h1.Write(data)
+
+	h2 := fnv.New64()
+	h2.Write(data)
+
+	return h1.Sum64(), h2.Sum64()
+}
+
+func (set *bloomSet) bitIndexes(i interface{}) []uint64 {
+	h1, h2 := hashes(i)
+	idxs := make([]uint64, set.k)
+	for j := uint64(0); j < set.k; j++ {
+		idxs[j] = (h1 + j*h2) % set.m
+	}
+	return idxs
+}
+
+func (set *bloomSet) testAndSet(i interface{}, setBits bool) bool {
+	allSet := true
+	for _, idx := range set.bitIndexes(i) {
+		word, bit := idx/64, idx%64
+		if set.bits[word]&(1<<bit) == 0 {
+			allSet = false
+			if !setBits {
+				return false
+			}
+			set.bits[word] |= 1 << bit
+		}
+	}
+	return allSet
+}
+
+// Add sets this element's k bits. Returns true unless every one of those
+// bits was already set, i.e. unless the element (or a hash collision
+// standing in for it) already appeared to be a member.
+func (set *bloomSet) Add(i interface{}) bool {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	alreadyPresent := set.testAndSet(i, true)
+	if !alreadyPresent {
+		set.n++
+	}
+	return !alreadyPresent
+}
+
+// Contains checks whether every one of i's k bits is set. May return a
+// false positive; never returns a false negative.
+func (set *bloomSet) Contains(i ...interface{}) bool {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	for _, elem := range i {
+		if !set.testAndSet(elem, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cardinality estimates the number of distinct elements added, via
+// -m/k * ln(1 - X/m), where X is the number of bits currently set. This
+// is the standard Bloom filter cardinality estimator; it is not exact, is
+// closest to the true count while the filter is lightly loaded, and grows
+// less precise as the filter fills.
+func (set *bloomSet) Cardinality() int {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	x := 0
+	for _, w := range set.bits {
+		x += bits.OnesCount64(w)
+	}
+	if x == 0 {
+		return 0
+	}
+	if uint64(x) >= set.m {
+		// Filter is saturated; the estimator diverges to infinity, so
+		// fall back to the exact count of successful Adds.
+		return int(set.n)
+	}
+	estimate := -float64(set.m) / float64(set.k) * math.Log(1-float64(x)/float64(set.m))
+	return int(math.Round(estimate))
+}
+
+func (set *bloomSet) Clear() {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for i := range set.bits {
+		set.bits[i] = 0
+	}
+	set.n = 0
+}
+
+// Remove is unsupported: a Bloom filter cannot clear an individual
+// element's bits without risking false negatives for other elements that
+// happen to share them. Calling Remove is a no-op.
+func (set *bloomSet) Remove(i interface{}) {}
+
+func (set *bloomSet) sameShape(other Set) (*bloomSet, bool) {
+	o, ok := other.(*bloomSet)
+	if !ok || o.m != set.m || o.k != set.k {
+		return nil, false
+	}
+	return o, true
+}
+
+// Union is only supported against another Bloom set with the same m and k
+// (i.e. created with the same expectedN/falsePositiveRate); it's computed
+// as a bitwise OR of the two underlying bit arrays. Mixing with any other
+// Set kind panics, since a Bloom filter can't absorb arbitrary elements
+// without knowing their hashes fit this filter's parameters.
+func (set *bloomSet) Union(other Set) Set {
+	o, ok := set.sameShape(other)
+	if !ok {
+		panic("mapset: bloomSet.Union requires another bloomSet with equal m/k parameters")
+	}
+
+	set.mu.RLock()
+	o.mu.RLock()
+	defer set.mu.RUnlock()
+	defer o.mu.RUnlock()
+
+	result := &bloomSet{bits: make([]uint64, len(set.bits)), m: set.m, k: set.k}
+	for i := range set.bits {
+		result.bits[i] = set.bits[i] | o.bits[i]
+	}
+	return result
+}
+
+// Intersect is only supported against another Bloom set with the same m
+// and k, computed as a bitwise AND. See Union.
+func (set *bloomSet) Intersect(other Set) Set {
+	o, ok := set.sameShape(other)
+	if !ok {
+		panic("mapset: bloomSet.Intersect requires another bloomSet with equal m/k parameters")
+	}
+
+	set.mu.RLock()
+	o.mu.RLock()
+	defer set.mu.RUnlock()
+	defer o.mu.RUnlock()
+
+	result := &bloomSet{bits: make([]uint64, len(set.bits)), m: set.m, k: set.k}
+	for i := range set.bits {
+		result.bits[i] = set.bits[i] & o.bits[i]
+	}
+	return result
+}
+
+// Difference, SymmetricDifference, IsSubset and friends would require
+// enumerating elements, which a Bloom filter cannot do; these panic
+// rather than silently returning a meaningless answer.
+func (set *bloomSet) Difference(other Set) Set {
+	panic("mapset: bloomSet does not support Difference; elements are not enumerable")
+}
+
+func (set *bloomSet) SymmetricDifference(other Set) Set {
+	panic("mapset: bloomSet does not support SymmetricDifference; elements are not enumerable")
+}
+
+func (set *bloomSet) IsSubset(other Set) bool {
+	panic("mapset: bloomSet does not support IsSubset; elements are not enumerable")
+}
+
+func (set *bloomSet) IsProperSubset(other Set) bool {
+	panic("mapset: bloomSet does not support IsProperSubset; elements are not enumerable")
+}
+
+func (set *bloomSet) IsSuperset(other Set) bool {
+	panic("mapset: bloomSet does not support IsSuperset; elements are not enumerable")
+}
+
+func (set *bloomSet) IsProperSuperset(other Set) bool {
+	panic("mapset: bloomSet does not support IsProperSuperset; elements are not enumerable")
+}
+
+// Each, Iter and Iterator never yield anything: a Bloom filter stores no
+// elements, only their hashes' bit positions, so there's nothing to range
+// over. Rather than panicking on code paths that generically range over
+// any Set (which would turn a NewBloomSet drop-in into a surprise crash
+// the first time something calls Each/Iter/Iterator/ToSlice/Pop), these
+// behave exactly like an empty Set; callers that need real enumeration
+// should keep a separate non-approximate Set alongside the Bloom filter.
+func (set *bloomSet) Each(cb func(interface{}) bool) {}
+
+func (set *bloomSet) Iter() <-chan interface{} {
+	ch := make(chan interface{})
+	close(ch)
+	return ch
+}
+
+func (set *bloomSet) Iterator() *Iterator {
+	iterator, ch, _ := newIterator()
+	close(ch)
+	return iterator
+}
+
+func (set *bloomSet) Equal(other Set) bool {
+	o, ok := set.sameShape(other)
+	if !ok {
+		return false
+	}
+	set.mu.RLock()
+	o.mu.RLock()
+	defer set.mu.RUnlock()
+	defer o.mu.RUnlock()
+
+	for i := range set.bits {
+		if set.bits[i] != o.bits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *bloomSet) Clone() Set {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	clone := &bloomSet{bits: make([]uint64, len(set.bits)), m: set.m, k: set.k, n: set.n}
+	copy(clone.bits, set.bits)
+	return clone
+}
+
+func (set *bloomSet) String() string {
+	return fmt.Sprintf("BloomSet{~%d elements, %d bits, %d hashes}", set.Cardinality(), set.m, set.k)
+}
+
+// Pop never has an element to return, for the same reason as Each/Iter;
+// it always reports the set as empty rather than panicking.
+func (set *bloomSet) Pop() interface{} {
+	return nil
+}
+
+func (set *bloomSet) PowerSet() Set {
+	panic("mapset: bloomSet does not support PowerSet; elements are not enumerable")
+}
+
+func (set *bloomSet) CartesianProduct(other Set) Set {
+	panic("mapset: bloomSet does not support CartesianProduct; elements are not enumerable")
+}
+
+// ToSlice always returns an empty slice, for the same reason as Each/Iter.
+func (set *bloomSet) ToSlice() []interface{} {
+	return nil
+}
+
+func (set *bloomSet) MarshalJSON() ([]byte, error) {
+	return nil, fmt.Errorf("mapset: bloomSet does not support MarshalJSON; elements are not enumerable")
+}
+
+func (set *bloomSet) UnmarshalJSON(p []byte) error {
+	return fmt.Errorf("mapset: bloomSet does not support UnmarshalJSON; elements are not enumerable")
+}
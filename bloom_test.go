@@ -0,0 +1,183 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBloomSetAddContains(t *testing.T) {
+	s := NewBloomSet(1000, 0.01)
+
+	if s.Contains("a") {
+		t.Fatal("Contains(\"a\") = true before Add")
+	}
+	if !s.Add("a") {
+		t.Fatal("Add(\"a\") = false on first insert")
+	}
+	if !s.Contains("a") {
+		t.Fatal("Contains(\"a\") = false after Add")
+	}
+	if s.Add("a") {
+		t.Fatal("Add(\"a\") = true on duplicate insert")
+	}
+}
+
+func TestBloomSetCardinalityTracksDistinctAdds(t *testing.T) {
+	s := NewBloomSet(1000, 0.01)
+	for i := 0; i < 100; i++ {
+		s.Add(fmt.Sprintf("elem-%d", i))
+	}
+
+	// The estimator is most precise at light load; at 10% of expectedN it
+	// should be close, not exact.
+	card := s.Cardinality()
+	if card < 90 || card > 110 {
+		t.Fatalf("Cardinality() = %d, want approximately 100", card)
+	}
+}
+
+func TestBloomSetClear(t *testing.T) {
+	s := NewBloomSet(100, 0.01)
+	s.Add("a")
+	s.Clear()
+	if s.Contains("a") {
+		t.Fatal("Contains(\"a\") = true after Clear")
+	}
+	if s.Cardinality() != 0 {
+		t.Fatalf("Cardinality() = %d after Clear, want 0", s.Cardinality())
+	}
+}
+
+func TestBloomSetRemoveIsNoOp(t *testing.T) {
+	s := NewBloomSet(100, 0.01)
+	s.Add("a")
+	s.Remove("a")
+	if !s.Contains("a") {
+		t.Fatal("Contains(\"a\") = false after Remove; Remove should be a no-op")
+	}
+}
+
+func TestBloomSetUnionIntersectSameShape(t *testing.T) {
+	a := NewBloomSet(100, 0.01)
+	b := NewBloomSet(100, 0.01)
+	a.Add("x")
+	b.Add("y")
+
+	union := a.Union(b)
+	if !union.Contains("x") || !union.Contains("y") {
+		t.Fatalf("Union missing an element: %v", union)
+	}
+
+	intersect := a.Intersect(b)
+	if intersect.Contains("x") || intersect.Contains("y") {
+		t.Fatalf("Intersect of disjoint sets should contain neither: %v", intersect)
+	}
+}
+
+func TestBloomSetUnionMismatchedShapePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Union of mismatched bloomSets did not panic")
+		}
+	}()
+	a := NewBloomSet(100, 0.01)
+	b := NewBloomSet(100000, 0.01)
+	a.Union(b)
+}
+
+func TestBloomSetUnionWithOtherKindPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Union with a non-bloomSet did not panic")
+		}
+	}()
+	a := NewBloomSet(100, 0.01)
+	a.Union(NewSet("x"))
+}
+
+// TestBloomSetEnumerationMethodsActLikeEmpty documents that Each, Iter,
+// Iterator, ToSlice and Pop never panic: a bloomSet stores no elements, so
+// they behave as if the set were empty instead of crashing generic code
+// that ranges over any Set.
+func TestBloomSetEnumerationMethodsActLikeEmpty(t *testing.T) {
+	s := NewBloomSet(100, 0.01)
+	s.Add("a")
+
+	called := false
+	s.Each(func(elem interface{}) bool {
+		called = true
+		return false
+	})
+	if called {
+		t.Fatal("Each invoked its callback on a bloomSet")
+	}
+
+	if _, ok := <-s.Iter(); ok {
+		t.Fatal("Iter yielded an element from a bloomSet")
+	}
+
+	it := s.Iterator()
+	if _, ok := <-it.C; ok {
+		t.Fatal("Iterator yielded an element from a bloomSet")
+	}
+
+	if slice := s.ToSlice(); len(slice) != 0 {
+		t.Fatalf("ToSlice() = %v, want empty", slice)
+	}
+
+	if popped := s.Pop(); popped != nil {
+		t.Fatalf("Pop() = %v, want nil", popped)
+	}
+}
+
+func TestBloomSetUnsupportedOpsPanic(t *testing.T) {
+	s := NewBloomSet(100, 0.01)
+	other := NewBloomSet(100, 0.01)
+
+	ops := map[string]func(){
+		"Difference":          func() { s.Difference(other) },
+		"SymmetricDifference": func() { s.SymmetricDifference(other) },
+		"IsSubset":            func() { s.IsSubset(other) },
+		"IsProperSubset":      func() { s.IsProperSubset(other) },
+		"IsSuperset":          func() { s.IsSuperset(other) },
+		"IsProperSuperset":    func() { s.IsProperSuperset(other) },
+		"PowerSet":            func() { s.PowerSet() },
+		"CartesianProduct":    func() { s.CartesianProduct(other) },
+	}
+
+	for name, op := range ops {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			op()
+		}()
+	}
+}
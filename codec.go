@@ -0,0 +1,145 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SetCodec controls how a Set is encoded to and decoded from the string
+// representation used by Value and Scan. Swap DefaultCodec to change how
+// every set in the process is persisted, e.g. via database/sql or GORM.
+type SetCodec interface {
+	// Encode renders items as a string suitable for storage in a single
+	// database column.
+	Encode(items []interface{}) (string, error)
+
+	// Decode parses a string previously produced by Encode back into a
+	// slice of elements.
+	Decode(data string) ([]interface{}, error)
+
+	// SQLType returns the column type this codec is best stored as,
+	// e.g. for use with GORM's Migrator when generating a schema.
+	SQLType() string
+}
+
+// DefaultCodec is the SetCodec used by every Value and Scan call in the
+// process; there is currently no way to select a different codec per
+// Set instance or per column. It defaults to JSONCodec.
+//
+// Reassigning DefaultCodec is not goroutine-safe: Value and Scan read
+// it without synchronization, so swapping it while other goroutines may
+// be persisting or loading a Set is a data race. Set it once at
+// startup, before any Set is used with database/sql or GORM.
+var DefaultCodec SetCodec = JSONCodec{}
+
+// JSONCodec encodes a set as a JSON array, e.g. `["a","b","c"]`.
+//
+// It does not round trip numeric types: encoding/json has no way to
+// recover whether a decoded JSON number was originally an int, an
+// int64, a float32 or a float64, so Decode always produces float64
+// elements regardless of what was passed to Encode. A Set built from
+// NewSet(1, 2, 3) and round tripped through Value/Scan will come back
+// holding float64(1), float64(2), float64(3), which compares unequal to
+// the original ints under Equal/Contains. Callers that need numeric
+// fields to survive a round trip should compare against the decoded
+// float64 form, or use a codec that preserves type information.
+type JSONCodec struct{}
+
+// Encode implements SetCodec.
+func (JSONCodec) Encode(items []interface{}) (string, error) {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Decode implements SetCodec.
+func (JSONCodec) Decode(data string) ([]interface{}, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var items []interface{}
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SQLType implements SetCodec.
+func (JSONCodec) SQLType() string {
+	return "JSON"
+}
+
+// CSVCodec encodes a set as a Delimiter-separated string of its elements'
+// fmt.Sprint representation, e.g. "a,b,c". It's lossy for anything other
+// than strings, since every element round-trips as a string.
+//
+// It also can't distinguish an empty set from a set containing exactly
+// one empty-string element: both encode to "", and Decode("") always
+// returns no elements, so a lone "" is silently dropped on the way
+// back. Avoid CSVCodec for sets that may contain "" as a member.
+type CSVCodec struct {
+	// Delimiter separates encoded elements. Defaults to "," when empty.
+	Delimiter string
+}
+
+// Encode implements SetCodec.
+func (c CSVCodec) Encode(items []interface{}) (string, error) {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, fmt.Sprint(item))
+	}
+	return strings.Join(parts, c.delimiter()), nil
+}
+
+// Decode implements SetCodec.
+func (c CSVCodec) Decode(data string) ([]interface{}, error) {
+	if data == "" {
+		return nil, nil
+	}
+	parts := strings.Split(data, c.delimiter())
+	items := make([]interface{}, len(parts))
+	for i, p := range parts {
+		items[i] = p
+	}
+	return items, nil
+}
+
+// SQLType implements SetCodec.
+func (CSVCodec) SQLType() string {
+	return "TEXT"
+}
+
+func (c CSVCodec) delimiter() string {
+	if c.Delimiter == "" {
+		return ","
+	}
+	return c.Delimiter
+}
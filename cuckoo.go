@@ -0,0 +1,447 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+const (
+	cuckooBucketSize = 4
+	cuckooMaxKicks   = 500
+)
+
+// cuckooSet is an approximate Set backed by a Cuckoo filter: an array of
+// buckets, each holding up to cuckooBucketSize one-byte fingerprints. Like
+// bloomSet it never stores the actual elements, so Contains may return a
+// false positive and methods that would need to enumerate elements (Each,
+// Iter, Iterator, ToSlice, Pop) behave as if the set were empty rather
+// than panicking. Unlike a Bloom filter it supports Remove without
+// needing to rebuild the whole structure, at the cost of potentially
+// needing to relocate entries on insert (an "eviction chain") when both
+// of an element's candidate buckets are full.
+type cuckooSet struct {
+	mu      sync.Mutex
+	buckets [][cuckooBucketSize]byte
+	mask    uint64 // len(buckets)-1; len(buckets) is a power of two
+	n       int
+}
+
+// NewCuckooSet creates a Set backed by a Cuckoo filter sized to hold
+// roughly expectedN elements: expectedN/cuckooBucketSize buckets of
+// one-byte fingerprints, rounded up to a power of two. falsePositiveRate
+// does not affect the filter's size; the false-positive rate follows from
+// the one-byte fingerprints and the bucket size.
+func NewCuckooSet(expectedN uint, falsePositiveRate float64) Set {
+	n := expectedN
+	if n < 1 {
+		n = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	numBuckets := nextPowerOfTwo(uint64(n) / cuckooBucketSize)
+	if numBuckets < 1 {
+		numBuckets = 1
+	}
+
+	return &cuckooSet{
+		buckets: make([][cuckooBucketSize]byte, numBuckets),
+		mask:    numBuckets - 1,
+	}
+}
+
+func nextPowerOfTwo(n uint64) uint64 {
+	if n == 0 {
+		return 1
+	}
+	p := uint64(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// fingerprintAndIndexes derives i's one-byte fingerprint plus its two
+// candidate bucket indexes. The fingerprint is never 0, since 0 marks an
+// empty slot.
+func (set *cuckooSet) fingerprintAndIndexes(i interface{}) (byte, uint64, uint64) {
+	h1, h2 := hashes(i)
+
+	fp := byte(h1) ^ byte(h1>>8) ^ byte(h1>>16) ^ byte(h1>>24)
+	if fp == 0 {
+		fp = 1
+	}
+
+	idx1 := h2 & set.mask
+
+	fh := fnv.New64a()
+	fh.Write([]byte{fp})
+	idx2 := (idx1 ^ fh.Sum64()) & set.mask
+
+	return fp, idx1, idx2
+}
+
+func (set *cuckooSet) insertInto(idx uint64, fp byte) bool {
+	bucket := &set.buckets[idx]
+	for slot, v := range bucket {
+		if v == 0 {
+			bucket[slot] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// Add inserts i's fingerprint into one of its two candidate buckets.
+//
+// Its bool result matches the Set interface's contract ("was this newly
+// added") in the common case, but unlike every map-backed Set in this
+// package it can't always tell "already present" and "insert failed,
+// element silently dropped" apart, since both report false: if the
+// filter is saturated and the standard Cuckoo insertion algorithm can't
+// find a free slot within cuckooMaxKicks, the element is dropped rather
+// than stored, and that also surfaces as Add returning false. Callers
+// that need to tell these two cases apart — e.g. to know whether they
+// can trust Contains going forward — should use TryAdd instead.
+func (set *cuckooSet) Add(i interface{}) bool {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	_, inserted := set.addLocked(i)
+	return inserted
+}
+
+// TryAdd behaves like Add, but reports separately whether i was dropped
+// because the filter is full (full=true) rather than because it was
+// already present (added=false, full=false). Use this wherever silently
+// losing an element would be a correctness problem, since Add's plain
+// bool can't make that distinction.
+func (set *cuckooSet) TryAdd(i interface{}) (added bool, full bool) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	alreadyPresent, inserted := set.addLocked(i)
+	if inserted {
+		return true, false
+	}
+	return false, !alreadyPresent
+}
+
+// addLocked does the actual insertion work shared by Add and TryAdd.
+// Caller must hold set.mu. alreadyPresent and inserted are never both
+// true.
+func (set *cuckooSet) addLocked(i interface{}) (alreadyPresent, inserted bool) {
+	fp, idx1, idx2 := set.fingerprintAndIndexes(i)
+
+	if set.containsFpLocked(fp, idx1, idx2) {
+		return true, false
+	}
+
+	if set.insertFingerprintLocked(fp, idx1) {
+		set.n++
+		return false, true
+	}
+	return false, false
+}
+
+// altIndex returns the other of fp's two candidate bucket indexes, given
+// one of them. Cuckoo filters derive it as idx XOR hash(fp), which is its
+// own inverse: applying it to either candidate index yields the other.
+func (set *cuckooSet) altIndex(fp byte, idx uint64) uint64 {
+	fh := fnv.New64a()
+	fh.Write([]byte{fp})
+	return (idx ^ fh.Sum64()) & set.mask
+}
+
+// insertFingerprintLocked places fp into bucket idx1 or its alternate
+// bucket if there's a free slot in either. If both are full, it evicts a
+// random fingerprint from one of them and relocates the evicted
+// fingerprint to its own alternate bucket, repeating up to
+// cuckooMaxKicks times (the standard Cuckoo filter insertion algorithm).
+// If no free slot turns up, every relocation is undone and fp is not
+// stored. Caller must hold set.mu.
+func (set *cuckooSet) insertFingerprintLocked(fp byte, idx1 uint64) bool {
+	idx2 := set.altIndex(fp, idx1)
+
+	if set.insertInto(idx1, fp) || set.insertInto(idx2, fp) {
+		return true
+	}
+
+	idx := idx1
+	if rand.Intn(2) == 1 {
+		idx = idx2
+	}
+
+	type relocation struct {
+		idx  uint64
+		slot int
+	}
+	path := make([]relocation, 0, cuckooMaxKicks)
+
+	for kick := 0; kick < cuckooMaxKicks; kick++ {
+		slot := rand.Intn(cuckooBucketSize)
+		bucket := &set.buckets[idx]
+		fp, bucket[slot] = bucket[slot], fp
+		path = append(path, relocation{idx: idx, slot: slot})
+		idx = set.altIndex(fp, idx)
+
+		if set.insertInto(idx, fp) {
+			return true
+		}
+	}
+
+	// Filter is effectively full: undo the swaps in reverse order so every
+	// previously stored fingerprint is back in place and only the new one
+	// is left out.
+	for j := len(path) - 1; j >= 0; j-- {
+		bucket := &set.buckets[path[j].idx]
+		fp, bucket[path[j].slot] = bucket[path[j].slot], fp
+	}
+	return false
+}
+
+func (set *cuckooSet) containsFpLocked(fp byte, idx1, idx2 uint64) bool {
+	for _, v := range set.buckets[idx1] {
+		if v == fp {
+			return true
+		}
+	}
+	for _, v := range set.buckets[idx2] {
+		if v == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains checks both of i's candidate buckets for its fingerprint. May
+// return a false positive on a fingerprint collision; never a false
+// negative for elements that haven't been evicted.
+func (set *cuckooSet) Contains(i ...interface{}) bool {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	for _, elem := range i {
+		fp, idx1, idx2 := set.fingerprintAndIndexes(elem)
+		if !set.containsFpLocked(fp, idx1, idx2) {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove clears i's fingerprint from whichever of its two candidate
+// buckets holds it, if any. Unlike a Bloom filter, removing an element
+// that was added leaves every other element's fingerprint in place.
+// Removing an element that was never added can clear another element's
+// fingerprint when the two share a fingerprint and a candidate bucket.
+func (set *cuckooSet) Remove(i interface{}) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	fp, idx1, idx2 := set.fingerprintAndIndexes(i)
+	for _, idx := range []uint64{idx1, idx2} {
+		bucket := &set.buckets[idx]
+		for slot, v := range bucket {
+			if v == fp {
+				bucket[slot] = 0
+				set.n--
+				return
+			}
+		}
+	}
+}
+
+// Cardinality returns the number of fingerprints currently stored. This
+// undercounts if two distinct elements hashed to the same fingerprint and
+// only one slot was retained, and is otherwise exact.
+func (set *cuckooSet) Cardinality() int {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	return set.n
+}
+
+func (set *cuckooSet) Clear() {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	for i := range set.buckets {
+		set.buckets[i] = [cuckooBucketSize]byte{}
+	}
+	set.n = 0
+}
+
+func (set *cuckooSet) sameShape(other Set) (*cuckooSet, bool) {
+	o, ok := other.(*cuckooSet)
+	if !ok || o.mask != set.mask {
+		return nil, false
+	}
+	return o, true
+}
+
+// Union is only supported against another Cuckoo set with the same
+// bucket layout; fingerprints from other are re-inserted into a clone of
+// set via the normal Add path.
+func (set *cuckooSet) Union(other Set) Set {
+	o, ok := set.sameShape(other)
+	if !ok {
+		panic("mapset: cuckooSet.Union requires another cuckooSet with equal bucket layout")
+	}
+
+	result := set.Clone().(*cuckooSet)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	result.mu.Lock()
+	defer result.mu.Unlock()
+
+	for idx, bucket := range o.buckets {
+		for _, fp := range bucket {
+			if fp == 0 {
+				continue
+			}
+			if !result.containsFpLocked(fp, uint64(idx), result.altIndex(fp, uint64(idx))) {
+				if result.insertFingerprintLocked(fp, uint64(idx)) {
+					result.n++
+				}
+			}
+		}
+	}
+	return result
+}
+
+func (set *cuckooSet) Intersect(other Set) Set {
+	panic("mapset: cuckooSet does not support Intersect; elements are not enumerable")
+}
+
+func (set *cuckooSet) Difference(other Set) Set {
+	panic("mapset: cuckooSet does not support Difference; elements are not enumerable")
+}
+
+func (set *cuckooSet) SymmetricDifference(other Set) Set {
+	panic("mapset: cuckooSet does not support SymmetricDifference; elements are not enumerable")
+}
+
+func (set *cuckooSet) IsSubset(other Set) bool {
+	panic("mapset: cuckooSet does not support IsSubset; elements are not enumerable")
+}
+
+func (set *cuckooSet) IsProperSubset(other Set) bool {
+	panic("mapset: cuckooSet does not support IsProperSubset; elements are not enumerable")
+}
+
+func (set *cuckooSet) IsSuperset(other Set) bool {
+	panic("mapset: cuckooSet does not support IsSuperset; elements are not enumerable")
+}
+
+func (set *cuckooSet) IsProperSuperset(other Set) bool {
+	panic("mapset: cuckooSet does not support IsProperSuperset; elements are not enumerable")
+}
+
+// Each, Iter and Iterator never yield anything: like bloomSet, a Cuckoo
+// filter stores only fingerprints, not the elements themselves, so there's
+// nothing to range over. These behave as if the set were empty rather
+// than panicking, so generic code that ranges over any Set doesn't crash
+// just because it was handed a cuckooSet.
+func (set *cuckooSet) Each(cb func(interface{}) bool) {}
+
+func (set *cuckooSet) Iter() <-chan interface{} {
+	ch := make(chan interface{})
+	close(ch)
+	return ch
+}
+
+func (set *cuckooSet) Iterator() *Iterator {
+	iterator, ch, _ := newIterator()
+	close(ch)
+	return iterator
+}
+
+func (set *cuckooSet) Equal(other Set) bool {
+	o, ok := set.sameShape(other)
+	if !ok {
+		return false
+	}
+	set.mu.Lock()
+	o.mu.Lock()
+	defer set.mu.Unlock()
+	defer o.mu.Unlock()
+
+	for i := range set.buckets {
+		if set.buckets[i] != o.buckets[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *cuckooSet) Clone() Set {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	clone := &cuckooSet{
+		buckets: make([][cuckooBucketSize]byte, len(set.buckets)),
+		mask:    set.mask,
+		n:       set.n,
+	}
+	copy(clone.buckets, set.buckets)
+	return clone
+}
+
+func (set *cuckooSet) String() string {
+	return fmt.Sprintf("CuckooSet{%d elements, %d buckets}", set.Cardinality(), len(set.buckets))
+}
+
+// Pop never has an element to return, for the same reason as Each/Iter; it
+// always reports the set as empty rather than panicking.
+func (set *cuckooSet) Pop() interface{} {
+	return nil
+}
+
+func (set *cuckooSet) PowerSet() Set {
+	panic("mapset: cuckooSet does not support PowerSet; elements are not enumerable")
+}
+
+func (set *cuckooSet) CartesianProduct(other Set) Set {
+	panic("mapset: cuckooSet does not support CartesianProduct; elements are not enumerable")
+}
+
+// ToSlice always returns an empty slice, for the same reason as Each/Iter.
+func (set *cuckooSet) ToSlice() []interface{} {
+	return nil
+}
+
+func (set *cuckooSet) MarshalJSON() ([]byte, error) {
+	return nil, fmt.Errorf("mapset: cuckooSet does not support MarshalJSON; elements are not enumerable")
+}
+
+func (set *cuckooSet) UnmarshalJSON(p []byte) error {
+	return fmt.Errorf("mapset: cuckooSet does not support UnmarshalJSON; elements are not enumerable")
+}
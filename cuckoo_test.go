@@ -0,0 +1,197 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCuckooSetAddContainsRemove(t *testing.T) {
+	s := NewCuckooSet(1000, 0.01)
+
+	if s.Contains("a") {
+		t.Fatal("Contains(\"a\") = true before Add")
+	}
+	if !s.Add("a") {
+		t.Fatal("Add(\"a\") = false on first insert")
+	}
+	if !s.Contains("a") {
+		t.Fatal("Contains(\"a\") = false after Add")
+	}
+	if s.Add("a") {
+		t.Fatal("Add(\"a\") = true on duplicate insert")
+	}
+
+	s.Remove("a")
+	if s.Contains("a") {
+		t.Fatal("Contains(\"a\") = true after Remove")
+	}
+}
+
+func TestCuckooSetTryAddDistinguishesPresentFromFull(t *testing.T) {
+	s := NewCuckooSet(1000, 0.01).(*cuckooSet)
+
+	added, full := s.TryAdd("a")
+	if !added || full {
+		t.Fatalf("TryAdd(\"a\") on first insert = (%v, %v), want (true, false)", added, full)
+	}
+
+	added, full = s.TryAdd("a")
+	if added || full {
+		t.Fatalf("TryAdd(\"a\") on duplicate insert = (%v, %v), want (false, false)", added, full)
+	}
+
+	// Fill every slot in every bucket directly so the filter is
+	// guaranteed saturated, then confirm a new element reports full
+	// rather than silently looking like "already present".
+	for idx := range s.buckets {
+		for slot := range s.buckets[idx] {
+			s.buckets[idx][slot] = 0xFF
+		}
+	}
+	added, full = s.TryAdd("brand-new-element-not-in-filter")
+	if added || !full {
+		t.Fatalf("TryAdd on a saturated filter = (%v, %v), want (false, true)", added, full)
+	}
+}
+
+func TestCuckooSetCardinalityExact(t *testing.T) {
+	s := NewCuckooSet(1000, 0.01)
+	for i := 0; i < 50; i++ {
+		s.Add(fmt.Sprintf("elem-%d", i))
+	}
+	if s.Cardinality() != 50 {
+		t.Fatalf("Cardinality() = %d, want 50", s.Cardinality())
+	}
+
+	s.Remove("elem-0")
+	if s.Cardinality() != 49 {
+		t.Fatalf("Cardinality() = %d after Remove, want 49", s.Cardinality())
+	}
+}
+
+func TestCuckooSetClear(t *testing.T) {
+	s := NewCuckooSet(100, 0.01)
+	s.Add("a")
+	s.Clear()
+	if s.Contains("a") {
+		t.Fatal("Contains(\"a\") = true after Clear")
+	}
+	if s.Cardinality() != 0 {
+		t.Fatalf("Cardinality() = %d after Clear, want 0", s.Cardinality())
+	}
+}
+
+func TestCuckooSetUnionSameShape(t *testing.T) {
+	a := NewCuckooSet(100, 0.01)
+	b := NewCuckooSet(100, 0.01)
+	a.Add("x")
+	b.Add("y")
+
+	union := a.Union(b)
+	if !union.Contains("x") || !union.Contains("y") {
+		t.Fatalf("Union missing an element: %v", union)
+	}
+	// a itself must be unmodified by Union.
+	if a.Contains("y") {
+		t.Fatal("Union mutated its receiver")
+	}
+}
+
+func TestCuckooSetUnionMismatchedShapePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Union of mismatched cuckooSets did not panic")
+		}
+	}()
+	a := NewCuckooSet(100, 0.01)
+	b := NewCuckooSet(100000, 0.01)
+	a.Union(b)
+}
+
+// TestCuckooSetEnumerationMethodsActLikeEmpty documents that Each, Iter,
+// Iterator, ToSlice and Pop never panic: a cuckooSet stores only
+// fingerprints, so they behave as if the set were empty instead of
+// crashing generic code that ranges over any Set.
+func TestCuckooSetEnumerationMethodsActLikeEmpty(t *testing.T) {
+	s := NewCuckooSet(100, 0.01)
+	s.Add("a")
+
+	called := false
+	s.Each(func(elem interface{}) bool {
+		called = true
+		return false
+	})
+	if called {
+		t.Fatal("Each invoked its callback on a cuckooSet")
+	}
+
+	if _, ok := <-s.Iter(); ok {
+		t.Fatal("Iter yielded an element from a cuckooSet")
+	}
+
+	it := s.Iterator()
+	if _, ok := <-it.C; ok {
+		t.Fatal("Iterator yielded an element from a cuckooSet")
+	}
+
+	if slice := s.ToSlice(); len(slice) != 0 {
+		t.Fatalf("ToSlice() = %v, want empty", slice)
+	}
+
+	if popped := s.Pop(); popped != nil {
+		t.Fatalf("Pop() = %v, want nil", popped)
+	}
+}
+
+func TestCuckooSetUnsupportedOpsPanic(t *testing.T) {
+	s := NewCuckooSet(100, 0.01)
+	other := NewCuckooSet(100, 0.01)
+
+	ops := map[string]func(){
+		"Intersect":           func() { s.Intersect(other) },
+		"Difference":          func() { s.Difference(other) },
+		"SymmetricDifference": func() { s.SymmetricDifference(other) },
+		"IsSubset":            func() { s.IsSubset(other) },
+		"IsProperSubset":      func() { s.IsProperSubset(other) },
+		"IsSuperset":          func() { s.IsSuperset(other) },
+		"IsProperSuperset":    func() { s.IsProperSuperset(other) },
+		"PowerSet":            func() { s.PowerSet() },
+		"CartesianProduct":    func() { s.CartesianProduct(other) },
+	}
+
+	for name, op := range ops {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			op()
+		}()
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+// Set is a typed collection of unique elements, backed by map[T]struct{}
+// rather than the interface{} storage used by mapset.Set. T must satisfy
+// comparable so it can be used as a map key.
+type Set[T comparable] interface {
+	// Add adds an element to the set. Returns true if the element didn't
+	// already exist in the set.
+	Add(i T) bool
+
+	// Cardinality returns the number of elements in the set.
+	Cardinality() int
+
+	// Clear removes all elements from the set, leaving the empty set.
+	Clear()
+
+	// Clone returns a deep copy of the set.
+	Clone() Set[T]
+
+	// Contains returns true if all of the given elements are in the set.
+	Contains(i ...T) bool
+
+	// Difference returns a new set with all elements in the current set
+	// that are not present in other.
+	Difference(other Set[T]) Set[T]
+
+	// Equal determines whether two sets contain the same elements.
+	Equal(other Set[T]) bool
+
+	// Intersect returns a new set with the elements common to both sets.
+	Intersect(other Set[T]) Set[T]
+
+	// IsProperSubset determines whether every element in this set is in
+	// other, and the two sets are not equal.
+	IsProperSubset(other Set[T]) bool
+
+	// IsProperSuperset determines whether every element in other is in
+	// this set, and the two sets are not equal.
+	IsProperSuperset(other Set[T]) bool
+
+	// IsSubset determines whether every element in this set is in other.
+	IsSubset(other Set[T]) bool
+
+	// IsSuperset determines whether every element in other is in this set.
+	IsSuperset(other Set[T]) bool
+
+	// Each iterates over elements and invokes the passed callback for
+	// each element, stopping early if the callback returns true.
+	Each(func(T) bool)
+
+	// Iter returns a channel of elements that can be ranged over.
+	Iter() <-chan T
+
+	// Iterator returns an Iterator object that can be used to range over
+	// the set with the ability to stop early.
+	Iterator() *Iterator[T]
+
+	// Pop removes and returns an arbitrary element from the set. The
+	// second return value is false if the set was empty.
+	Pop() (T, bool)
+
+	// Remove removes a single element from the set.
+	Remove(i T)
+
+	// String provides a readable representation of the set.
+	String() string
+
+	// SymmetricDifference returns a new set with the elements that are
+	// in this set or other, but not both.
+	SymmetricDifference(other Set[T]) Set[T]
+
+	// ToSlice returns the elements of the current set as a slice.
+	ToSlice() []T
+
+	// Union returns a new set with all elements in both sets.
+	Union(other Set[T]) Set[T]
+
+	// MarshalJSON creates a JSON array from the set. The order of the
+	// elements in the array is not defined.
+	MarshalJSON() ([]byte, error)
+
+	// UnmarshalJSON recreates a set from a JSON array, this method
+	// expects the array to contain primitive values only.
+	UnmarshalJSON(b []byte) error
+}
+
+// NewSet creates and returns a new thread-safe Set[T] with the given
+// elements.
+func NewSet[T comparable](vals ...T) Set[T] {
+	s := newThreadSafeSet[T]()
+	for _, v := range vals {
+		s.Add(v)
+	}
+	return &s
+}
+
+// NewThreadUnsafeSet creates and returns a new non-thread-safe Set[T] with
+// the given elements.
+func NewThreadUnsafeSet[T comparable](vals ...T) Set[T] {
+	s := newThreadUnsafeSet[T]()
+	for _, v := range vals {
+		s.Add(v)
+	}
+	return &s
+}
+
+// OrderedPair represents a 2-tuple of values produced by CartesianProduct.
+type OrderedPair[A comparable, B comparable] struct {
+	First  A
+	Second B
+}
+
+// CartesianProduct returns a new set of OrderedPair objects made up of
+// every combination of elements between a and b, i.e. the cartesian
+// product of the two sets. Because Go methods cannot introduce their own
+// type parameters, this is a package-level function rather than a method
+// on Set[T].
+func CartesianProduct[A comparable, B comparable](a Set[A], b Set[B]) Set[OrderedPair[A, B]] {
+	result := NewThreadUnsafeSet[OrderedPair[A, B]]()
+	for i := range a.Iter() {
+		for j := range b.Iter() {
+			result.Add(OrderedPair[A, B]{First: i, Second: j})
+		}
+	}
+	return result
+}
+
+// PowerSet returns the power set of s: the set of all possible subsets of s,
+// including the empty set and s itself. As with CartesianProduct, this is a
+// package-level function because Set[Set[T]] can't be expressed as a method
+// on Set[T] without the receiver's type parameter leaking into the result.
+func PowerSet[T comparable](s Set[T]) Set[Set[T]] {
+	elements := s.ToSlice()
+	powerSet := NewThreadUnsafeSet[Set[T]]()
+	powerSet.Add(NewThreadUnsafeSet[T]())
+
+	for _, elem := range elements {
+		existing := powerSet.ToSlice()
+		for _, subset := range existing {
+			withElem := subset.Clone()
+			withElem.Add(elem)
+			powerSet.Add(withElem)
+		}
+	}
+	return powerSet
+}
@@ -0,0 +1,229 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"sort"
+	"testing"
+)
+
+// setsEqualFor runs the same battery of assertions against both the
+// thread-safe and thread-unsafe constructors, since they're expected to
+// behave identically and most bugs in one are copy-paste bugs in both.
+func setsEqualFor(t *testing.T, name string, newSet func(...int) Set[int]) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1, 2, 3)
+		if s.Cardinality() != 3 {
+			t.Fatalf("Cardinality() = %d, want 3", s.Cardinality())
+		}
+		if !s.Contains(1, 2, 3) {
+			t.Fatal("Contains(1, 2, 3) = false")
+		}
+		if s.Contains(4) {
+			t.Fatal("Contains(4) = true")
+		}
+		if !s.Add(4) {
+			t.Fatal("Add(4) = false on first insert")
+		}
+		if s.Add(4) {
+			t.Fatal("Add(4) = true on duplicate insert")
+		}
+
+		s.Remove(4)
+		if s.Contains(4) {
+			t.Fatal("Contains(4) = true after Remove")
+		}
+
+		clone := s.Clone()
+		if !clone.Equal(s) {
+			t.Fatal("Clone() is not Equal to original")
+		}
+		clone.Add(99)
+		if s.Contains(99) {
+			t.Fatal("mutating Clone() mutated the original")
+		}
+
+		s.Clear()
+		if s.Cardinality() != 0 {
+			t.Fatalf("Cardinality() = %d after Clear, want 0", s.Cardinality())
+		}
+	})
+}
+
+func TestSetBasicOps(t *testing.T) {
+	setsEqualFor(t, "threadsafe", NewSet[int])
+	setsEqualFor(t, "threadunsafe", NewThreadUnsafeSet[int])
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5, 6)
+
+	if !a.Union(b).Equal(NewSet(1, 2, 3, 4, 5, 6)) {
+		t.Errorf("Union = %v, want {1,2,3,4,5,6}", a.Union(b))
+	}
+	if !a.Intersect(b).Equal(NewSet(3, 4)) {
+		t.Errorf("Intersect = %v, want {3,4}", a.Intersect(b))
+	}
+	if !a.Difference(b).Equal(NewSet(1, 2)) {
+		t.Errorf("Difference = %v, want {1,2}", a.Difference(b))
+	}
+	if !a.SymmetricDifference(b).Equal(NewSet(1, 2, 5, 6)) {
+		t.Errorf("SymmetricDifference = %v, want {1,2,5,6}", a.SymmetricDifference(b))
+	}
+
+	if !NewSet(1, 2).IsSubset(a) {
+		t.Error("{1,2}.IsSubset(a) = false")
+	}
+	if !NewSet(1, 2).IsProperSubset(a) {
+		t.Error("{1,2}.IsProperSubset(a) = false")
+	}
+	if a.IsProperSubset(a) {
+		t.Error("a.IsProperSubset(a) = true")
+	}
+	if !a.IsSuperset(NewSet(1, 2)) {
+		t.Error("a.IsSuperset({1,2}) = false")
+	}
+}
+
+func TestSetEachAndIter(t *testing.T) {
+	s := NewSet(1, 2, 3)
+
+	var viaEach []int
+	s.Each(func(i int) bool {
+		viaEach = append(viaEach, i)
+		return false
+	})
+	sort.Ints(viaEach)
+	if len(viaEach) != 3 || viaEach[0] != 1 || viaEach[1] != 2 || viaEach[2] != 3 {
+		t.Fatalf("Each visited %v, want {1,2,3}", viaEach)
+	}
+
+	var viaIter []int
+	for i := range s.Iter() {
+		viaIter = append(viaIter, i)
+	}
+	sort.Ints(viaIter)
+	if len(viaIter) != 3 || viaIter[0] != 1 || viaIter[1] != 2 || viaIter[2] != 3 {
+		t.Fatalf("Iter yielded %v, want {1,2,3}", viaIter)
+	}
+}
+
+func TestSetIteratorStop(t *testing.T) {
+	s := NewSet(1, 2, 3)
+	it := s.Iterator()
+	<-it.C
+	it.Stop()
+
+	// it.C must eventually close once Stop is called, even if there was
+	// more to yield.
+	for range it.C {
+	}
+}
+
+func TestSetPop(t *testing.T) {
+	s := NewSet(1)
+	elem, ok := s.Pop()
+	if !ok || elem != 1 {
+		t.Fatalf("Pop() = (%v, %v), want (1, true)", elem, ok)
+	}
+	if _, ok := s.Pop(); ok {
+		t.Fatal("Pop() on an empty set returned ok=true")
+	}
+}
+
+func TestSetToSlice(t *testing.T) {
+	s := NewSet(1, 2, 3)
+	slice := s.ToSlice()
+	sort.Ints(slice)
+	if len(slice) != 3 || slice[0] != 1 || slice[1] != 2 || slice[2] != 3 {
+		t.Fatalf("ToSlice() = %v, want [1 2 3]", slice)
+	}
+}
+
+func TestSetString(t *testing.T) {
+	s := NewSet(1)
+	if s.String() != "Set{1}" {
+		t.Fatalf("String() = %q, want %q", s.String(), "Set{1}")
+	}
+}
+
+func TestSetMarshalUnmarshalJSON(t *testing.T) {
+	s := NewSet(1, 2, 3)
+	b, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	round := NewSet[int]()
+	if err := round.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !round.Equal(s) {
+		t.Fatalf("round-tripped set %v != original %v", round, s)
+	}
+}
+
+func TestPowerSet(t *testing.T) {
+	s := NewThreadUnsafeSet(1, 2)
+	ps := PowerSet(s)
+
+	if ps.Cardinality() != 4 {
+		t.Fatalf("PowerSet cardinality = %d, want 4 (2^2)", ps.Cardinality())
+	}
+
+	var sawEmpty, sawFull bool
+	ps.Each(func(subset Set[int]) bool {
+		switch subset.Cardinality() {
+		case 0:
+			sawEmpty = true
+		case 2:
+			if subset.Contains(1, 2) {
+				sawFull = true
+			}
+		}
+		return false
+	})
+	if !sawEmpty {
+		t.Error("PowerSet did not contain the empty set")
+	}
+	if !sawFull {
+		t.Error("PowerSet did not contain the full set")
+	}
+}
+
+func TestCartesianProduct(t *testing.T) {
+	a := NewThreadUnsafeSet(1, 2)
+	b := NewThreadUnsafeSet("x", "y")
+
+	product := CartesianProduct(a, b)
+	if product.Cardinality() != 4 {
+		t.Fatalf("CartesianProduct cardinality = %d, want 4", product.Cardinality())
+	}
+	if !product.Contains(OrderedPair[int, string]{First: 1, Second: "x"}) {
+		t.Error("CartesianProduct missing pair {1, x}")
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import "sync"
+
+type threadSafeSet[T comparable] struct {
+	s threadUnsafeSet[T]
+	sync.RWMutex
+}
+
+func newThreadSafeSet[T comparable]() threadSafeSet[T] {
+	return threadSafeSet[T]{s: newThreadUnsafeSet[T]()}
+}
+
+func (set *threadSafeSet[T]) Add(i T) bool {
+	set.Lock()
+	defer set.Unlock()
+	return set.s.Add(i)
+}
+
+func (set *threadSafeSet[T]) Contains(i ...T) bool {
+	set.RLock()
+	defer set.RUnlock()
+	return set.s.Contains(i...)
+}
+
+func (set *threadSafeSet[T]) IsSubset(other Set[T]) bool {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	return set.s.IsSubset(&o.s)
+}
+
+func (set *threadSafeSet[T]) IsProperSubset(other Set[T]) bool {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	return set.s.IsProperSubset(&o.s)
+}
+
+func (set *threadSafeSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(set)
+}
+
+func (set *threadSafeSet[T]) IsProperSuperset(other Set[T]) bool {
+	return other.IsProperSubset(set)
+}
+
+func (set *threadSafeSet[T]) Union(other Set[T]) Set[T] {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	unsafeUnion := set.s.Union(&o.s).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{s: *unsafeUnion}
+}
+
+func (set *threadSafeSet[T]) Intersect(other Set[T]) Set[T] {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	unsafeIntersection := set.s.Intersect(&o.s).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{s: *unsafeIntersection}
+}
+
+func (set *threadSafeSet[T]) Difference(other Set[T]) Set[T] {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	unsafeDifference := set.s.Difference(&o.s).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{s: *unsafeDifference}
+}
+
+func (set *threadSafeSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	unsafeDifference := set.s.SymmetricDifference(&o.s).(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{s: *unsafeDifference}
+}
+
+func (set *threadSafeSet[T]) Clear() {
+	set.Lock()
+	set.s = newThreadUnsafeSet[T]()
+	set.Unlock()
+}
+
+func (set *threadSafeSet[T]) Remove(i T) {
+	set.Lock()
+	delete(set.s, i)
+	set.Unlock()
+}
+
+func (set *threadSafeSet[T]) Cardinality() int {
+	set.RLock()
+	defer set.RUnlock()
+	return len(set.s)
+}
+
+func (set *threadSafeSet[T]) Each(cb func(T) bool) {
+	set.RLock()
+	defer set.RUnlock()
+	for elem := range set.s {
+		if cb(elem) {
+			break
+		}
+	}
+}
+
+func (set *threadSafeSet[T]) Iter() <-chan T {
+	ch := make(chan T)
+	go func() {
+		set.RLock()
+		defer set.RUnlock()
+
+		for elem := range set.s {
+			ch <- elem
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (set *threadSafeSet[T]) Iterator() *Iterator[T] {
+	iterator, ch, stopCh := newIterator[T]()
+
+	go func() {
+		set.RLock()
+	L:
+		for elem := range set.s {
+			select {
+			case <-stopCh:
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+		set.RUnlock()
+	}()
+
+	return iterator
+}
+
+func (set *threadSafeSet[T]) Equal(other Set[T]) bool {
+	o := other.(*threadSafeSet[T])
+
+	set.RLock()
+	o.RLock()
+	defer set.RUnlock()
+	defer o.RUnlock()
+
+	return set.s.Equal(&o.s)
+}
+
+func (set *threadSafeSet[T]) Clone() Set[T] {
+	set.RLock()
+	defer set.RUnlock()
+
+	unsafeClone := set.s.Clone().(*threadUnsafeSet[T])
+	return &threadSafeSet[T]{s: *unsafeClone}
+}
+
+func (set *threadSafeSet[T]) String() string {
+	set.RLock()
+	defer set.RUnlock()
+	return set.s.String()
+}
+
+func (set *threadSafeSet[T]) Pop() (T, bool) {
+	set.Lock()
+	defer set.Unlock()
+	return set.s.Pop()
+}
+
+func (set *threadSafeSet[T]) ToSlice() []T {
+	keys := make([]T, 0, set.Cardinality())
+	set.RLock()
+	defer set.RUnlock()
+	for elem := range set.s {
+		keys = append(keys, elem)
+	}
+	return keys
+}
+
+func (set *threadSafeSet[T]) MarshalJSON() ([]byte, error) {
+	set.RLock()
+	defer set.RUnlock()
+	return set.s.MarshalJSON()
+}
+
+func (set *threadSafeSet[T]) UnmarshalJSON(p []byte) error {
+	set.Lock()
+	defer set.Unlock()
+	return set.s.UnmarshalJSON(p)
+}
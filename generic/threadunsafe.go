@@ -0,0 +1,252 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type threadUnsafeSet[T comparable] map[T]struct{}
+
+func newThreadUnsafeSet[T comparable]() threadUnsafeSet[T] {
+	return make(threadUnsafeSet[T])
+}
+
+func (s *threadUnsafeSet[T]) Add(i T) bool {
+	_, found := (*s)[i]
+	if found {
+		return false
+	}
+	(*s)[i] = struct{}{}
+	return true
+}
+
+func (s *threadUnsafeSet[T]) Contains(i ...T) bool {
+	for _, val := range i {
+		if _, ok := (*s)[val]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet[T]) IsSubset(other Set[T]) bool {
+	o := other.(*threadUnsafeSet[T])
+	for elem := range *s {
+		if !o.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet[T]) IsProperSubset(other Set[T]) bool {
+	return s.IsSubset(other) && s.Cardinality() < other.Cardinality()
+}
+
+func (s *threadUnsafeSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s *threadUnsafeSet[T]) IsProperSuperset(other Set[T]) bool {
+	return other.IsProperSubset(s)
+}
+
+func (s *threadUnsafeSet[T]) Union(other Set[T]) Set[T] {
+	o := other.(*threadUnsafeSet[T])
+
+	unionedSet := newThreadUnsafeSet[T]()
+	for elem := range *s {
+		unionedSet.Add(elem)
+	}
+	for elem := range *o {
+		unionedSet.Add(elem)
+	}
+	return &unionedSet
+}
+
+func (s *threadUnsafeSet[T]) Intersect(other Set[T]) Set[T] {
+	o := other.(*threadUnsafeSet[T])
+
+	intersection := newThreadUnsafeSet[T]()
+	smaller, larger := s, o
+	if o.Cardinality() < s.Cardinality() {
+		smaller, larger = o, s
+	}
+	for elem := range *smaller {
+		if larger.Contains(elem) {
+			intersection.Add(elem)
+		}
+	}
+	return &intersection
+}
+
+func (s *threadUnsafeSet[T]) Difference(other Set[T]) Set[T] {
+	o := other.(*threadUnsafeSet[T])
+
+	difference := newThreadUnsafeSet[T]()
+	for elem := range *s {
+		if !o.Contains(elem) {
+			difference.Add(elem)
+		}
+	}
+	return &difference
+}
+
+func (s *threadUnsafeSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	o := other.(*threadUnsafeSet[T])
+
+	aDiff := s.Difference(o).(*threadUnsafeSet[T])
+	bDiff := o.Difference(s).(*threadUnsafeSet[T])
+	return aDiff.Union(bDiff)
+}
+
+func (s *threadUnsafeSet[T]) Clear() {
+	*s = newThreadUnsafeSet[T]()
+}
+
+func (s *threadUnsafeSet[T]) Remove(i T) {
+	delete(*s, i)
+}
+
+func (s *threadUnsafeSet[T]) Cardinality() int {
+	return len(*s)
+}
+
+func (s *threadUnsafeSet[T]) Each(cb func(T) bool) {
+	for elem := range *s {
+		if cb(elem) {
+			break
+		}
+	}
+}
+
+func (s *threadUnsafeSet[T]) Iter() <-chan T {
+	ch := make(chan T)
+	go func() {
+		for elem := range *s {
+			ch <- elem
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (s *threadUnsafeSet[T]) Iterator() *Iterator[T] {
+	iterator, ch, stopCh := newIterator[T]()
+
+	go func() {
+	L:
+		for elem := range *s {
+			select {
+			case <-stopCh:
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+	}()
+
+	return iterator
+}
+
+func (s *threadUnsafeSet[T]) Equal(other Set[T]) bool {
+	o := other.(*threadUnsafeSet[T])
+
+	if s.Cardinality() != o.Cardinality() {
+		return false
+	}
+	for elem := range *s {
+		if !o.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet[T]) Clone() Set[T] {
+	clonedSet := newThreadUnsafeSet[T]()
+	for elem := range *s {
+		clonedSet.Add(elem)
+	}
+	return &clonedSet
+}
+
+func (s *threadUnsafeSet[T]) String() string {
+	items := make([]string, 0, len(*s))
+	for elem := range *s {
+		items = append(items, fmt.Sprintf("%v", elem))
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (s *threadUnsafeSet[T]) Pop() (T, bool) {
+	for elem := range *s {
+		delete(*s, elem)
+		return elem, true
+	}
+	var zero T
+	return zero, false
+}
+
+func (s *threadUnsafeSet[T]) ToSlice() []T {
+	keys := make([]T, 0, s.Cardinality())
+	for elem := range *s {
+		keys = append(keys, elem)
+	}
+	return keys
+}
+
+func (s *threadUnsafeSet[T]) MarshalJSON() ([]byte, error) {
+	items := make([]string, 0, s.Cardinality())
+
+	for elem := range *s {
+		b, err := json.Marshal(elem)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, string(b))
+	}
+
+	return []byte(fmt.Sprintf("[%s]", strings.Join(items, ","))), nil
+}
+
+func (s *threadUnsafeSet[T]) UnmarshalJSON(b []byte) error {
+	var i []T
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	if err := d.Decode(&i); err != nil {
+		return err
+	}
+
+	for _, v := range i {
+		s.Add(v)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import "sync"
+
+// SetEventOp identifies the kind of mutation a SetEvent describes.
+type SetEventOp int
+
+const (
+	// SetEventAdded is published when an element is added to an
+	// ObservableSet.
+	SetEventAdded SetEventOp = iota
+
+	// SetEventRemoved is published when an element is removed from an
+	// ObservableSet, including via Pop.
+	SetEventRemoved
+
+	// SetEventCleared is published when an ObservableSet is emptied via
+	// Clear. Item is nil for this event.
+	SetEventCleared
+)
+
+// SetEvent describes a single mutation of an ObservableSet.
+type SetEvent struct {
+	Op   SetEventOp
+	Item interface{}
+}
+
+// OverflowPolicy controls what an ObservableSet does for a subscriber
+// whose channel is full when a new SetEvent is published.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest discards the new event, leaving the
+	// subscriber's buffered events untouched. This is the default.
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowDropOldest discards the oldest buffered event to make
+	// room for the new one, coalescing the subscriber's view forward
+	// at the cost of losing older history.
+	OverflowDropOldest
+)
+
+// DefaultSubscriberBuffer is the channel buffer size Subscribe uses when
+// an ObservableSet is created with NewObservableSet's default options.
+const DefaultSubscriberBuffer = 16
+
+// ObservableSet wraps a Set and publishes a SetEvent to every subscriber
+// each time it's mutated via Add, Remove, Pop or Clear. It satisfies Set
+// itself by embedding one, so it's a drop-in replacement anywhere a Set is
+// expected; every method it doesn't override (including Union, Intersect,
+// Difference, Clone and so on) delegates straight to the wrapped Set and
+// returns its own, non-observable result, so derived sets are never
+// observable unless explicitly wrapped again with NewObservableSet.
+type ObservableSet struct {
+	Set
+
+	mu         sync.Mutex
+	subs       map[int]chan SetEvent
+	nextSubID  int
+	bufferSize int
+	policy     OverflowPolicy
+}
+
+// ObservableOption configures an ObservableSet constructed via
+// NewObservableSet.
+type ObservableOption func(*ObservableSet)
+
+// WithSubscriberBuffer sets the channel buffer size used for each new
+// subscriber.
+func WithSubscriberBuffer(n int) ObservableOption {
+	return func(o *ObservableSet) {
+		if n > 0 {
+			o.bufferSize = n
+		}
+	}
+}
+
+// WithOverflowPolicy sets the policy applied to a subscriber whose
+// channel is full when a new event is published.
+func WithOverflowPolicy(p OverflowPolicy) ObservableOption {
+	return func(o *ObservableSet) {
+		o.policy = p
+	}
+}
+
+// NewObservableSet wraps an existing Set so that Add, Remove, Pop and
+// Clear publish a SetEvent to every current subscriber.
+func NewObservableSet(underlying Set, opts ...ObservableOption) *ObservableSet {
+	o := &ObservableSet{
+		Set:        underlying,
+		subs:       make(map[int]chan SetEvent),
+		bufferSize: DefaultSubscriberBuffer,
+		policy:     OverflowDropNewest,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Subscribe registers a new subscriber and returns a channel of the
+// events published from this point on, plus a function to unsubscribe
+// and release it. The returned channel is buffered per the
+// ObservableSet's configured buffer size and overflow policy; it is
+// closed once the caller unsubscribes.
+func (o *ObservableSet) Subscribe() (<-chan SetEvent, func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	id := o.nextSubID
+	o.nextSubID++
+
+	ch := make(chan SetEvent, o.bufferSize)
+	o.subs[id] = ch
+
+	unsubscribe := func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if ch, ok := o.subs[id]; ok {
+			delete(o.subs, id)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+func (o *ObservableSet) publish(ev SetEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, ch := range o.subs {
+		select {
+		case ch <- ev:
+		default:
+			switch o.policy {
+			case OverflowDropOldest:
+				select {
+				case <-ch:
+				default:
+				}
+				select {
+				case ch <- ev:
+				default:
+				}
+			case OverflowDropNewest:
+				// Drop ev; nothing to do.
+			}
+		}
+	}
+}
+
+// Add adds i to the underlying set and, if it wasn't already present,
+// publishes a SetEventAdded to every subscriber.
+func (o *ObservableSet) Add(i interface{}) bool {
+	added := o.Set.Add(i)
+	if added {
+		o.publish(SetEvent{Op: SetEventAdded, Item: i})
+	}
+	return added
+}
+
+// Remove removes i from the underlying set and, if it was present,
+// publishes a SetEventRemoved to every subscriber. Removing an element
+// that isn't a member is a no-op, same as the underlying Set, and
+// publishes nothing.
+//
+// The underlying Set's Remove doesn't report whether it actually removed
+// anything, so the check-and-remove has to be serialized on o.mu itself:
+// without that, two goroutines racing to remove the same element could
+// both observe it present and both publish a SetEventRemoved for a single
+// removal. o.mu is released again before publish re-acquires it, so this
+// doesn't lock against itself.
+func (o *ObservableSet) Remove(i interface{}) {
+	o.mu.Lock()
+	removed := o.Set.Contains(i)
+	if removed {
+		o.Set.Remove(i)
+	}
+	o.mu.Unlock()
+
+	if removed {
+		o.publish(SetEvent{Op: SetEventRemoved, Item: i})
+	}
+}
+
+// Pop removes and returns an arbitrary element from the underlying set,
+// publishing a SetEventRemoved for it unless the set was empty.
+func (o *ObservableSet) Pop() interface{} {
+	i := o.Set.Pop()
+	if i != nil {
+		o.publish(SetEvent{Op: SetEventRemoved, Item: i})
+	}
+	return i
+}
+
+// Clear empties the underlying set and publishes a single
+// SetEventCleared to every subscriber.
+func (o *ObservableSet) Clear() {
+	o.Set.Clear()
+	o.publish(SetEvent{Op: SetEventCleared})
+}
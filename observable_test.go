@@ -0,0 +1,170 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestObservableSetPublishesAddAndRemove(t *testing.T) {
+	o := NewObservableSet(NewSet())
+	events, unsubscribe := o.Subscribe()
+	defer unsubscribe()
+
+	o.Add("a")
+	o.Remove("a")
+
+	ev := <-events
+	if ev.Op != SetEventAdded || ev.Item != "a" {
+		t.Fatalf("first event = %+v, want Added/a", ev)
+	}
+	ev = <-events
+	if ev.Op != SetEventRemoved || ev.Item != "a" {
+		t.Fatalf("second event = %+v, want Removed/a", ev)
+	}
+}
+
+func TestObservableSetDoesNotPublishForDuplicateAdd(t *testing.T) {
+	o := NewObservableSet(NewSet())
+	o.Add("a")
+
+	events, unsubscribe := o.Subscribe()
+	defer unsubscribe()
+
+	if o.Add("a") {
+		t.Fatal("Add(\"a\") = true for an already-present element")
+	}
+
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event for duplicate Add: %+v", ev)
+	default:
+	}
+}
+
+func TestObservableSetDoesNotPublishForMissingRemove(t *testing.T) {
+	o := NewObservableSet(NewSet())
+	events, unsubscribe := o.Subscribe()
+	defer unsubscribe()
+
+	o.Remove("never-added")
+
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event removing a non-member: %+v", ev)
+	default:
+	}
+}
+
+func TestObservableSetUnionIsNotObservable(t *testing.T) {
+	o := NewObservableSet(NewSet("a"))
+	union := o.Union(NewSet("b"))
+
+	if _, ok := union.(*ObservableSet); ok {
+		t.Fatal("Union of an ObservableSet returned an ObservableSet")
+	}
+}
+
+// TestObservableSetConcurrentRemoveSameKeyPublishesOnce races many
+// goroutines removing the SAME element concurrently. Remove used to do a
+// check-then-act Contains-then-Remove with no lock spanning the two
+// calls, so two goroutines could both observe the element present and
+// both publish a SetEventRemoved for what was really a single removal;
+// this reproduced as up to 15 duplicate events with enough contention.
+func TestObservableSetConcurrentRemoveSameKeyPublishesOnce(t *testing.T) {
+	o := NewObservableSet(NewSet("x"), WithSubscriberBuffer(64))
+	events, unsubscribe := o.Subscribe()
+	defer unsubscribe()
+
+	const removers = 32
+	var wg sync.WaitGroup
+	wg.Add(removers)
+	for i := 0; i < removers; i++ {
+		go func() {
+			defer wg.Done()
+			o.Remove("x")
+		}()
+	}
+	wg.Wait()
+
+	removedCount := 0
+	for {
+		select {
+		case ev := <-events:
+			if ev.Op == SetEventRemoved && ev.Item == "x" {
+				removedCount++
+			}
+		default:
+			if removedCount != 1 {
+				t.Fatalf("published %d SetEventRemoved for one element, want 1", removedCount)
+			}
+			return
+		}
+	}
+}
+
+// TestObservableSetConcurrentSubscribersRace exercises Add/Remove from
+// many goroutines against many concurrently (un)subscribing subscribers,
+// so that running with -race catches any lock ordering or map access bug
+// in publish/Subscribe.
+func TestObservableSetConcurrentSubscribersRace(t *testing.T) {
+	o := NewObservableSet(NewSet(), WithSubscriberBuffer(4), WithOverflowPolicy(OverflowDropOldest))
+
+	const writers = 8
+	const subscribers = 8
+	const perWriter = 200
+
+	var wg sync.WaitGroup
+
+	for s := 0; s < subscribers; s++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events, unsubscribe := o.Subscribe()
+			defer unsubscribe()
+			for i := 0; i < perWriter; i++ {
+				select {
+				case <-events:
+				default:
+				}
+			}
+		}()
+	}
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				o.Add(w*perWriter + i)
+				o.Remove(w*perWriter + i)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,659 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// hamtBits is the number of hash bits consumed per HAMT level.
+const hamtBits = 5
+
+// hamtLeaf holds every element sharing hash at the depth the leaf lives
+// at. Its elems slice has more than one entry only when two elements hash
+// identically all the way down to the 32-bit hash being exhausted.
+type hamtLeaf struct {
+	hash  uint32
+	elems []interface{}
+}
+
+// hamtEntry is one slot of a hamtNode's compact children array: either a
+// leaf or a pointer to a deeper node, never both.
+type hamtEntry struct {
+	node *hamtNode
+	leaf *hamtLeaf
+}
+
+// hamtNode is one level of the trie: a 32-bit bitmap marking which of the
+// 32 possible slots at this level are occupied, plus a children array
+// compacted down to only the occupied slots (indexed by
+// popcount(bitmap & (bit-1))). Add/Remove never mutate a hamtNode in
+// place; they return a new node that shares every untouched child with
+// the original, which is what makes the structure persistent.
+type hamtNode struct {
+	bitmap   uint32
+	children []hamtEntry
+}
+
+func hamtHash(i interface{}) uint32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%v", i)
+	return h.Sum32()
+}
+
+func slotBit(hash uint32, shift uint) uint32 {
+	idx := (hash >> shift) & (1<<hamtBits - 1)
+	return 1 << idx
+}
+
+func slotIndex(bitmap, bit uint32) int {
+	return bits.OnesCount32(bitmap & (bit - 1))
+}
+
+func withInsertedChild(children []hamtEntry, pos int, e hamtEntry) []hamtEntry {
+	out := make([]hamtEntry, len(children)+1)
+	copy(out, children[:pos])
+	out[pos] = e
+	copy(out[pos+1:], children[pos:])
+	return out
+}
+
+func withReplacedChild(children []hamtEntry, pos int, e hamtEntry) []hamtEntry {
+	out := make([]hamtEntry, len(children))
+	copy(out, children)
+	out[pos] = e
+	return out
+}
+
+func withRemovedChild(children []hamtEntry, pos int) []hamtEntry {
+	out := make([]hamtEntry, len(children)-1)
+	copy(out, children[:pos])
+	copy(out[pos:], children[pos+1:])
+	return out
+}
+
+// hamtAdd returns a new root containing el (hashing to hash) in addition
+// to everything already reachable from n, and whether el was not already
+// present. n itself, and every subtree not on the path to el, is reused
+// unchanged in the result.
+func hamtAdd(n *hamtNode, hash uint32, el interface{}, shift uint) (*hamtNode, bool) {
+	if n == nil {
+		n = &hamtNode{}
+	}
+
+	bit := slotBit(hash, shift)
+	pos := slotIndex(n.bitmap, bit)
+
+	if n.bitmap&bit == 0 {
+		children := withInsertedChild(n.children, pos, hamtEntry{leaf: &hamtLeaf{hash: hash, elems: []interface{}{el}}})
+		return &hamtNode{bitmap: n.bitmap | bit, children: children}, true
+	}
+
+	entry := n.children[pos]
+
+	if entry.node != nil {
+		newSub, added := hamtAdd(entry.node, hash, el, shift+hamtBits)
+		if !added {
+			return n, false
+		}
+		return &hamtNode{bitmap: n.bitmap, children: withReplacedChild(n.children, pos, hamtEntry{node: newSub})}, true
+	}
+
+	leaf := entry.leaf
+	if leaf.hash == hash {
+		for _, e := range leaf.elems {
+			if e == el {
+				return n, false
+			}
+		}
+		if shift+hamtBits >= 32 {
+			newElems := make([]interface{}, len(leaf.elems)+1)
+			copy(newElems, leaf.elems)
+			newElems[len(leaf.elems)] = el
+			newLeaf := hamtEntry{leaf: &hamtLeaf{hash: hash, elems: newElems}}
+			return &hamtNode{bitmap: n.bitmap, children: withReplacedChild(n.children, pos, newLeaf)}, true
+		}
+	}
+
+	// Either a different hash landed in the same slot, or an identical
+	// hash that hasn't yet exhausted the 32 bits: push both the
+	// existing leaf and the new element one level deeper.
+	var sub *hamtNode
+	sub, _ = hamtAdd(sub, leaf.hash, leaf.elems[0], shift+hamtBits)
+	for _, e := range leaf.elems[1:] {
+		sub, _ = hamtAdd(sub, leaf.hash, e, shift+hamtBits)
+	}
+	sub, _ = hamtAdd(sub, hash, el, shift+hamtBits)
+
+	return &hamtNode{bitmap: n.bitmap, children: withReplacedChild(n.children, pos, hamtEntry{node: sub})}, true
+}
+
+// hamtRemove returns a new root with el removed, and whether it was
+// present. A nil result means the resulting subtree is empty.
+func hamtRemove(n *hamtNode, hash uint32, el interface{}, shift uint) (*hamtNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	bit := slotBit(hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	pos := slotIndex(n.bitmap, bit)
+	entry := n.children[pos]
+
+	if entry.leaf != nil {
+		leaf := entry.leaf
+		if leaf.hash != hash {
+			return n, false
+		}
+		found := -1
+		for idx, e := range leaf.elems {
+			if e == el {
+				found = idx
+				break
+			}
+		}
+		if found < 0 {
+			return n, false
+		}
+		if len(leaf.elems) == 1 {
+			return shrink(n, pos, bit)
+		}
+		newElems := make([]interface{}, 0, len(leaf.elems)-1)
+		newElems = append(newElems, leaf.elems[:found]...)
+		newElems = append(newElems, leaf.elems[found+1:]...)
+		newLeaf := hamtEntry{leaf: &hamtLeaf{hash: hash, elems: newElems}}
+		return &hamtNode{bitmap: n.bitmap, children: withReplacedChild(n.children, pos, newLeaf)}, true
+	}
+
+	newSub, removed := hamtRemove(entry.node, hash, el, shift+hamtBits)
+	if !removed {
+		return n, false
+	}
+	if newSub == nil {
+		return shrink(n, pos, bit)
+	}
+	return &hamtNode{bitmap: n.bitmap, children: withReplacedChild(n.children, pos, hamtEntry{node: newSub})}, true
+}
+
+func shrink(n *hamtNode, pos int, bit uint32) (*hamtNode, bool) {
+	newBitmap := n.bitmap &^ bit
+	if newBitmap == 0 {
+		return nil, true
+	}
+	return &hamtNode{bitmap: newBitmap, children: withRemovedChild(n.children, pos)}, true
+}
+
+func hamtContains(n *hamtNode, hash uint32, el interface{}, shift uint) bool {
+	if n == nil {
+		return false
+	}
+	bit := slotBit(hash, shift)
+	if n.bitmap&bit == 0 {
+		return false
+	}
+	entry := n.children[slotIndex(n.bitmap, bit)]
+	if entry.leaf != nil {
+		if entry.leaf.hash != hash {
+			return false
+		}
+		for _, e := range entry.leaf.elems {
+			if e == el {
+				return true
+			}
+		}
+		return false
+	}
+	return hamtContains(entry.node, hash, el, shift+hamtBits)
+}
+
+func hamtEach(n *hamtNode, cb func(interface{}) bool) bool {
+	if n == nil {
+		return false
+	}
+	for _, entry := range n.children {
+		if entry.leaf != nil {
+			for _, e := range entry.leaf.elems {
+				if cb(e) {
+					return true
+				}
+			}
+			continue
+		}
+		if hamtEach(entry.node, cb) {
+			return true
+		}
+	}
+	return false
+}
+
+// hamtDiff walks newNode and oldNode in lockstep, collecting elements
+// reachable only from one side into added/removed. Subtrees that are
+// pointer-identical (i.e. shared via structural sharing) are skipped
+// without descending into them, which is what keeps this O(changes)
+// rather than O(N).
+func hamtDiff(newNode, oldNode *hamtNode, added, removed *[]interface{}) {
+	if newNode == oldNode {
+		return
+	}
+	if newNode == nil {
+		hamtEach(oldNode, func(e interface{}) bool { *removed = append(*removed, e); return false })
+		return
+	}
+	if oldNode == nil {
+		hamtEach(newNode, func(e interface{}) bool { *added = append(*added, e); return false })
+		return
+	}
+
+	both := newNode.bitmap | oldNode.bitmap
+	for both != 0 {
+		bit := both & (-both)
+		both &^= bit
+
+		inNew := newNode.bitmap&bit != 0
+		inOld := oldNode.bitmap&bit != 0
+
+		var newEntry, oldEntry hamtEntry
+		if inNew {
+			newEntry = newNode.children[slotIndex(newNode.bitmap, bit)]
+		}
+		if inOld {
+			oldEntry = oldNode.children[slotIndex(oldNode.bitmap, bit)]
+		}
+
+		switch {
+		case inNew && !inOld:
+			collectEntry(newEntry, added)
+		case inOld && !inNew:
+			collectEntry(oldEntry, removed)
+		case newEntry.node != nil && oldEntry.node != nil:
+			hamtDiff(newEntry.node, oldEntry.node, added, removed)
+		case newEntry.leaf != nil && oldEntry.leaf != nil:
+			diffLeaves(newEntry.leaf, oldEntry.leaf, added, removed)
+		default:
+			// A leaf on one side and a subnode on the other: this happens
+			// when an Add between the two versions pushed a leaf down into
+			// a new subnode because another element landed in its slot, or
+			// when the two roots are unrelated. Fall back to a plain set
+			// difference of the two sides' elements.
+			var newElems, oldElems []interface{}
+			collectEntry(newEntry, &newElems)
+			collectEntry(oldEntry, &oldElems)
+			diffSlices(newElems, oldElems, added, removed)
+		}
+	}
+}
+
+func collectEntry(e hamtEntry, out *[]interface{}) {
+	if e.leaf != nil {
+		*out = append(*out, e.leaf.elems...)
+		return
+	}
+	hamtEach(e.node, func(elem interface{}) bool { *out = append(*out, elem); return false })
+}
+
+func diffLeaves(newLeaf, oldLeaf *hamtLeaf, added, removed *[]interface{}) {
+	diffSlices(newLeaf.elems, oldLeaf.elems, added, removed)
+}
+
+func diffSlices(newElems, oldElems []interface{}, added, removed *[]interface{}) {
+	for _, e := range newElems {
+		found := false
+		for _, o := range oldElems {
+			if e == o {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*added = append(*added, e)
+		}
+	}
+	for _, o := range oldElems {
+		found := false
+		for _, e := range newElems {
+			if o == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*removed = append(*removed, o)
+		}
+	}
+}
+
+// PersistentSet is a Set backed by a Hash Array Mapped Trie. Add/Remove
+// on the Set interface mutate the PersistentSet's current root in place
+// (so it behaves like any other Set from a caller's perspective), but the
+// underlying trie nodes are never mutated: each operation builds a new
+// root that shares every untouched subtree with the previous one in
+// O(log32 N) time and space. AddP/RemoveP expose that persistence
+// directly by returning the new version as an independent Set, leaving
+// the receiver (and anyone still holding its previous root, e.g. via
+// Diff) untouched.
+type PersistentSet struct {
+	mu    sync.RWMutex
+	root  *hamtNode
+	count int
+}
+
+// NewPersistentSet creates an empty PersistentSet.
+func NewPersistentSet() Set {
+	return &PersistentSet{}
+}
+
+func newPersistentSetFrom(root *hamtNode, count int) *PersistentSet {
+	return &PersistentSet{root: root, count: count}
+}
+
+// Add adds i to the set, mutating the receiver's current version in
+// place, and returns true if i wasn't already present. See AddP for a
+// non-mutating equivalent.
+func (set *PersistentSet) Add(i interface{}) bool {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	newRoot, added := hamtAdd(set.root, hamtHash(i), i, 0)
+	if added {
+		set.root = newRoot
+		set.count++
+	}
+	return added
+}
+
+// AddP returns a new PersistentSet with i added, sharing every untouched
+// node with the receiver. The receiver is not modified.
+func (set *PersistentSet) AddP(i interface{}) Set {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	newRoot, added := hamtAdd(set.root, hamtHash(i), i, 0)
+	count := set.count
+	if added {
+		count++
+	}
+	return newPersistentSetFrom(newRoot, count)
+}
+
+// Remove removes i from the set, mutating the receiver's current version
+// in place. See RemoveP for a non-mutating equivalent.
+func (set *PersistentSet) Remove(i interface{}) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	newRoot, removed := hamtRemove(set.root, hamtHash(i), i, 0)
+	if removed {
+		set.root = newRoot
+		set.count--
+	}
+}
+
+// RemoveP returns a new PersistentSet with i removed, sharing every
+// untouched node with the receiver. The receiver is not modified.
+func (set *PersistentSet) RemoveP(i interface{}) Set {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+
+	newRoot, removed := hamtRemove(set.root, hamtHash(i), i, 0)
+	count := set.count
+	if removed {
+		count--
+	}
+	return newPersistentSetFrom(newRoot, count)
+}
+
+// Diff compares the receiver's current version against an older version
+// of itself (typically a PersistentSet retained from before a run of
+// Add/Remove calls, or returned from an earlier AddP/RemoveP), and
+// returns the elements added and removed since. It runs in O(changes) by
+// skipping any subtree the two versions share a pointer to.
+func (set *PersistentSet) Diff(old Set) (added, removed Set) {
+	o, ok := old.(*PersistentSet)
+	if !ok {
+		panic("mapset: PersistentSet.Diff requires another PersistentSet produced from a common ancestor")
+	}
+
+	set.mu.RLock()
+	o.mu.RLock()
+	defer set.mu.RUnlock()
+	defer o.mu.RUnlock()
+
+	var addedElems, removedElems []interface{}
+	hamtDiff(set.root, o.root, &addedElems, &removedElems)
+
+	return NewThreadUnsafeSet(addedElems...), NewThreadUnsafeSet(removedElems...)
+}
+
+func (set *PersistentSet) Contains(i ...interface{}) bool {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	for _, elem := range i {
+		if !hamtContains(set.root, hamtHash(elem), elem, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *PersistentSet) Cardinality() int {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.count
+}
+
+func (set *PersistentSet) Clear() {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.root = nil
+	set.count = 0
+}
+
+func (set *PersistentSet) Clone() Set {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return newPersistentSetFrom(set.root, set.count)
+}
+
+func (set *PersistentSet) Each(cb func(interface{}) bool) {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	hamtEach(set.root, cb)
+}
+
+func (set *PersistentSet) Iter() <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		set.mu.RLock()
+		defer set.mu.RUnlock()
+		hamtEach(set.root, func(e interface{}) bool {
+			ch <- e
+			return false
+		})
+		close(ch)
+	}()
+	return ch
+}
+
+func (set *PersistentSet) Iterator() *Iterator {
+	iterator, ch, stopCh := newIterator()
+	go func() {
+		set.mu.RLock()
+		defer set.mu.RUnlock()
+		hamtEach(set.root, func(e interface{}) bool {
+			select {
+			case <-stopCh:
+				return true
+			case ch <- e:
+				return false
+			}
+		})
+		close(ch)
+	}()
+	return iterator
+}
+
+func (set *PersistentSet) ToSlice() []interface{} {
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	out := make([]interface{}, 0, set.count)
+	hamtEach(set.root, func(e interface{}) bool { out = append(out, e); return false })
+	return out
+}
+
+func (set *PersistentSet) String() string {
+	items := set.ToSlice()
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = fmt.Sprintf("%v", it)
+	}
+	return fmt.Sprintf("PersistentSet{%s}", strings.Join(parts, ", "))
+}
+
+func (set *PersistentSet) Pop() interface{} {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	var popped interface{}
+	found := false
+	hamtEach(set.root, func(e interface{}) bool {
+		popped = e
+		found = true
+		return true
+	})
+	if !found {
+		return nil
+	}
+	newRoot, _ := hamtRemove(set.root, hamtHash(popped), popped, 0)
+	set.root = newRoot
+	set.count--
+	return popped
+}
+
+func (set *PersistentSet) IsSubset(other Set) bool {
+	isSubset := true
+	set.Each(func(elem interface{}) bool {
+		if !other.Contains(elem) {
+			isSubset = false
+			return true
+		}
+		return false
+	})
+	return isSubset
+}
+
+func (set *PersistentSet) IsProperSubset(other Set) bool {
+	return set.IsSubset(other) && set.Cardinality() < other.Cardinality()
+}
+
+func (set *PersistentSet) IsSuperset(other Set) bool {
+	return other.IsSubset(set)
+}
+
+func (set *PersistentSet) IsProperSuperset(other Set) bool {
+	return other.IsProperSubset(set)
+}
+
+func (set *PersistentSet) Equal(other Set) bool {
+	if set.Cardinality() != other.Cardinality() {
+		return false
+	}
+	return set.IsSubset(other)
+}
+
+func (set *PersistentSet) Union(other Set) Set {
+	result := NewPersistentSet()
+	set.Each(func(elem interface{}) bool { result.Add(elem); return false })
+	other.Each(func(elem interface{}) bool { result.Add(elem); return false })
+	return result
+}
+
+func (set *PersistentSet) Intersect(other Set) Set {
+	result := NewPersistentSet()
+	set.Each(func(elem interface{}) bool {
+		if other.Contains(elem) {
+			result.Add(elem)
+		}
+		return false
+	})
+	return result
+}
+
+func (set *PersistentSet) Difference(other Set) Set {
+	result := NewPersistentSet()
+	set.Each(func(elem interface{}) bool {
+		if !other.Contains(elem) {
+			result.Add(elem)
+		}
+		return false
+	})
+	return result
+}
+
+func (set *PersistentSet) SymmetricDifference(other Set) Set {
+	result := set.Difference(other).(*PersistentSet)
+	other.Each(func(elem interface{}) bool {
+		if !set.Contains(elem) {
+			result.Add(elem)
+		}
+		return false
+	})
+	return result
+}
+
+func (set *PersistentSet) PowerSet() Set {
+	flattened := newThreadUnsafeSet()
+	set.Each(func(elem interface{}) bool { flattened.Add(elem); return false })
+	return flattened.PowerSet()
+}
+
+func (set *PersistentSet) CartesianProduct(other Set) Set {
+	flattened := newThreadUnsafeSet()
+	set.Each(func(elem interface{}) bool { flattened.Add(elem); return false })
+	return flattened.CartesianProduct(other)
+}
+
+func (set *PersistentSet) MarshalJSON() ([]byte, error) {
+	flattened := newThreadUnsafeSet()
+	set.Each(func(elem interface{}) bool { flattened.Add(elem); return false })
+	return flattened.MarshalJSON()
+}
+
+func (set *PersistentSet) UnmarshalJSON(p []byte) error {
+	flattened := newThreadUnsafeSet()
+	if err := flattened.UnmarshalJSON(p); err != nil {
+		return err
+	}
+	for elem := range flattened {
+		set.Add(elem)
+	}
+	return nil
+}
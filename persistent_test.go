@@ -0,0 +1,298 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPersistentSetBasicOps(t *testing.T) {
+	s := NewPersistentSet()
+
+	if !s.Add(1) {
+		t.Fatal("Add(1) = false on first insert")
+	}
+	if s.Add(1) {
+		t.Fatal("Add(1) = true on duplicate insert")
+	}
+	if !s.Contains(1) {
+		t.Fatal("Contains(1) = false after Add")
+	}
+	if s.Cardinality() != 1 {
+		t.Fatalf("Cardinality() = %d, want 1", s.Cardinality())
+	}
+
+	s.Remove(1)
+	if s.Contains(1) {
+		t.Fatal("Contains(1) = true after Remove")
+	}
+	if s.Cardinality() != 0 {
+		t.Fatalf("Cardinality() = %d after Remove, want 0", s.Cardinality())
+	}
+}
+
+// TestPersistentSetManyElementsForcesDeepTrie inserts enough elements that
+// the HAMT must descend past its first 5-bit level for at least some of
+// them (and, with enough elements, handle hash collisions at depth), since
+// shallow tests wouldn't exercise hamtAdd/hamtRemove/hamtContains's
+// recursive node-splitting path at all.
+func TestPersistentSetManyElementsForcesDeepTrie(t *testing.T) {
+	s := NewPersistentSet()
+	const n = 5000
+
+	for i := 0; i < n; i++ {
+		if !s.Add(i) {
+			t.Fatalf("Add(%d) = false on first insert", i)
+		}
+	}
+	if s.Cardinality() != n {
+		t.Fatalf("Cardinality() = %d, want %d", s.Cardinality(), n)
+	}
+	for i := 0; i < n; i++ {
+		if !s.Contains(i) {
+			t.Fatalf("Contains(%d) = false after Add", i)
+		}
+	}
+
+	for i := 0; i < n; i += 2 {
+		s.Remove(i)
+	}
+	if s.Cardinality() != n/2 {
+		t.Fatalf("Cardinality() = %d after removing evens, want %d", s.Cardinality(), n/2)
+	}
+	for i := 1; i < n; i += 2 {
+		if !s.Contains(i) {
+			t.Fatalf("Contains(%d) = false after removing only evens", i)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		if s.Contains(i) {
+			t.Fatalf("Contains(%d) = true after Remove", i)
+		}
+	}
+}
+
+// TestPersistentSetAddPDoesNotMutateReceiver is the core persistence
+// guarantee: AddP/RemoveP must return a new version while leaving the
+// receiver, and anything else still holding it, completely untouched.
+func TestPersistentSetAddPDoesNotMutateReceiver(t *testing.T) {
+	v1 := NewPersistentSet()
+	v1.(*PersistentSet).Add(1)
+	v1.(*PersistentSet).Add(2)
+
+	v2 := v1.(*PersistentSet).AddP(3)
+
+	if v1.Contains(3) {
+		t.Fatal("AddP mutated the receiver")
+	}
+	if !v2.Contains(1) || !v2.Contains(2) || !v2.Contains(3) {
+		t.Fatalf("AddP result missing elements: %v", v2)
+	}
+	if v1.Cardinality() != 2 {
+		t.Fatalf("receiver Cardinality() = %d after AddP, want 2", v1.Cardinality())
+	}
+	if v2.Cardinality() != 3 {
+		t.Fatalf("AddP result Cardinality() = %d, want 3", v2.Cardinality())
+	}
+}
+
+func TestPersistentSetRemovePDoesNotMutateReceiver(t *testing.T) {
+	v1 := NewPersistentSet()
+	v1.(*PersistentSet).Add(1)
+	v1.(*PersistentSet).Add(2)
+
+	v2 := v1.(*PersistentSet).RemoveP(1)
+
+	if !v1.Contains(1) {
+		t.Fatal("RemoveP mutated the receiver")
+	}
+	if v2.Contains(1) {
+		t.Fatal("RemoveP result still contains the removed element")
+	}
+	if !v2.Contains(2) {
+		t.Fatal("RemoveP result lost an untouched element")
+	}
+}
+
+func TestPersistentSetDiff(t *testing.T) {
+	old := NewPersistentSet()
+	old.(*PersistentSet).Add(1)
+	old.(*PersistentSet).Add(2)
+
+	current := old.(*PersistentSet).AddP(3).(*PersistentSet)
+	current = current.RemoveP(1).(*PersistentSet)
+
+	added, removed := current.Diff(old)
+
+	if added.Cardinality() != 1 || !added.Contains(3) {
+		t.Fatalf("Diff added = %v, want {3}", added)
+	}
+	if removed.Cardinality() != 1 || !removed.Contains(1) {
+		t.Fatalf("Diff removed = %v, want {1}", removed)
+	}
+}
+
+func TestPersistentSetDiffUnrelatedTypesPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Diff against a non-PersistentSet did not panic")
+		}
+	}()
+	s := NewPersistentSet().(*PersistentSet)
+	s.Diff(NewSet())
+}
+
+func TestPersistentSetClone(t *testing.T) {
+	s := NewPersistentSet()
+	s.Add(1)
+
+	clone := s.Clone()
+	clone.Add(2)
+
+	if s.Contains(2) {
+		t.Fatal("mutating Clone() mutated the original")
+	}
+	if !clone.Contains(1) || !clone.Contains(2) {
+		t.Fatalf("Clone() missing elements: %v", clone)
+	}
+}
+
+func TestPersistentSetEachIterToSlice(t *testing.T) {
+	s := NewPersistentSet()
+	for i := 0; i < 10; i++ {
+		s.Add(i)
+	}
+
+	seen := map[interface{}]bool{}
+	s.Each(func(elem interface{}) bool {
+		seen[elem] = true
+		return false
+	})
+	if len(seen) != 10 {
+		t.Fatalf("Each visited %d elements, want 10", len(seen))
+	}
+
+	seen = map[interface{}]bool{}
+	for elem := range s.Iter() {
+		seen[elem] = true
+	}
+	if len(seen) != 10 {
+		t.Fatalf("Iter yielded %d elements, want 10", len(seen))
+	}
+
+	if len(s.ToSlice()) != 10 {
+		t.Fatalf("ToSlice() returned %d elements, want 10", len(s.ToSlice()))
+	}
+}
+
+func TestPersistentSetIteratorStop(t *testing.T) {
+	s := NewPersistentSet()
+	for i := 0; i < 10; i++ {
+		s.Add(i)
+	}
+
+	it := s.Iterator()
+	<-it.C
+	it.Stop()
+	for range it.C {
+	}
+}
+
+func TestPersistentSetPop(t *testing.T) {
+	s := NewPersistentSet()
+	s.Add(1)
+
+	popped := s.Pop()
+	if popped != 1 {
+		t.Fatalf("Pop() = %v, want 1", popped)
+	}
+	if s.Contains(1) {
+		t.Fatal("Contains(1) = true after Pop")
+	}
+	if s.Pop() != nil {
+		t.Fatal("Pop() on an empty set did not return nil")
+	}
+}
+
+func TestPersistentSetAlgebra(t *testing.T) {
+	a := NewPersistentSet()
+	b := NewPersistentSet()
+	for i := 0; i < 5; i++ {
+		a.Add(i)
+	}
+	for i := 3; i < 8; i++ {
+		b.Add(i)
+	}
+
+	wantUnion := NewSet(0, 1, 2, 3, 4, 5, 6, 7)
+	if !a.Union(b).Equal(wantUnion) {
+		t.Errorf("Union = %v, want %v", a.Union(b), wantUnion)
+	}
+
+	wantIntersect := NewSet(3, 4)
+	if !a.Intersect(b).Equal(wantIntersect) {
+		t.Errorf("Intersect = %v, want %v", a.Intersect(b), wantIntersect)
+	}
+
+	wantDiff := NewSet(0, 1, 2)
+	if !a.Difference(b).Equal(wantDiff) {
+		t.Errorf("Difference = %v, want %v", a.Difference(b), wantDiff)
+	}
+
+	wantSymDiff := NewSet(0, 1, 2, 5, 6, 7)
+	if !a.SymmetricDifference(b).Equal(wantSymDiff) {
+		t.Errorf("SymmetricDifference = %v, want %v", a.SymmetricDifference(b), wantSymDiff)
+	}
+}
+
+func TestPersistentSetMarshalUnmarshalJSON(t *testing.T) {
+	s := NewPersistentSet()
+	for i := 0; i < 5; i++ {
+		s.Add(fmt.Sprintf("elem-%d", i))
+	}
+
+	b, err := s.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	round := NewPersistentSet()
+	if err := round.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !round.Equal(s) {
+		t.Fatalf("round-tripped set %v != original %v", round, s)
+	}
+}
+
+func TestPersistentSetString(t *testing.T) {
+	s := NewPersistentSet()
+	s.Add(1)
+	if got := s.String(); got != "PersistentSet{1}" {
+		t.Fatalf("String() = %q, want %q", got, "PersistentSet{1}")
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package mapset implements a simple and generic set collection.
+// Items stored within the set are unordered and unique. It supports
+// typical set operations: membership testing, intersection, union,
+// difference, symmetric difference and cloning.
+package mapset
+
+// Set is a collection of unique elements, stored as interface{} so any
+// comparable type can be used. It is implemented by the two built-in sets
+// returned by NewSet and NewThreadUnsafeSet, as well as by the other Set
+// implementations in this package (sharded, approximate, observable and
+// persistent sets). Not every implementation supports every method: the
+// approximate sets panic on methods that need to enumerate elements, and
+// the built-in sets' methods that take another Set panic unless it is of
+// the same implementation.
+type Set interface {
+	// Add adds an element to the set. Returns true if the element didn't
+	// already exist in the set.
+	Add(i interface{}) bool
+
+	// Cardinality returns the number of elements in the set.
+	Cardinality() int
+
+	// Clear removes all elements from the set, leaving the empty set.
+	Clear()
+
+	// Clone returns a deep copy of the set.
+	Clone() Set
+
+	// Contains returns true if all of the given elements are in the set.
+	Contains(i ...interface{}) bool
+
+	// Difference returns a new set with all elements in the current set
+	// that are not present in other.
+	Difference(other Set) Set
+
+	// Equal determines whether two sets contain the same elements.
+	Equal(other Set) bool
+
+	// Intersect returns a new set with the elements common to both sets.
+	Intersect(other Set) Set
+
+	// IsProperSubset determines whether every element in this set is in
+	// other, and the two sets are not equal.
+	IsProperSubset(other Set) bool
+
+	// IsProperSuperset determines whether every element in other is in
+	// this set, and the two sets are not equal.
+	IsProperSuperset(other Set) bool
+
+	// IsSubset determines whether every element in this set is in other.
+	IsSubset(other Set) bool
+
+	// IsSuperset determines whether every element in other is in this
+	// set.
+	IsSuperset(other Set) bool
+
+	// Each iterates over elements and invokes the passed callback for
+	// each element, stopping early if the callback returns true.
+	Each(func(interface{}) bool)
+
+	// Iter returns a channel of elements that can be ranged over.
+	Iter() <-chan interface{}
+
+	// Iterator returns an Iterator object that can be used to range over
+	// the set with the ability to stop early.
+	Iterator() *Iterator
+
+	// Remove removes a single element from the set.
+	Remove(i interface{})
+
+	// String provides a readable representation of the set.
+	String() string
+
+	// SymmetricDifference returns a new set with the elements that are
+	// in this set or other, but not both.
+	SymmetricDifference(other Set) Set
+
+	// ToSlice returns the elements of the current set as a slice.
+	ToSlice() []interface{}
+
+	// Union returns a new set with all elements in both sets.
+	Union(other Set) Set
+
+	// Pop removes and returns an arbitrary element from the set, or nil
+	// if the set is empty.
+	Pop() interface{}
+
+	// PowerSet returns the power set of the set: the set of all
+	// possible subsets, including the empty set and the set itself.
+	PowerSet() Set
+
+	// CartesianProduct returns a new set of OrderedPair objects made up
+	// of every combination of elements between this set and other.
+	CartesianProduct(other Set) Set
+
+	// MarshalJSON creates a JSON array from the set. The order of the
+	// elements in the array is not defined.
+	MarshalJSON() ([]byte, error)
+
+	// UnmarshalJSON recreates a set from a JSON array, this method
+	// expects the array to contain primitive values only.
+	UnmarshalJSON(b []byte) error
+}
+
+// NewSet creates and returns a new thread-safe Set with the given
+// elements.
+func NewSet(s ...interface{}) Set {
+	set := newThreadSafeSet()
+	for _, item := range s {
+		set.Add(item)
+	}
+	return &set
+}
+
+// NewThreadUnsafeSet creates and returns a new non-thread-safe Set with
+// the given elements.
+func NewThreadUnsafeSet(s ...interface{}) Set {
+	set := newThreadUnsafeSet()
+	for _, item := range s {
+		set.Add(item)
+	}
+	return &set
+}
+
+// OrderedPair represents a 2-tuple of values produced by CartesianProduct.
+type OrderedPair struct {
+	First  interface{}
+	Second interface{}
+}
+
+// Equal determines whether two OrderedPairs are equal.
+func (pair *OrderedPair) Equal(other OrderedPair) bool {
+	return pair.First == other.First && pair.Second == other.Second
+}
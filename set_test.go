@@ -0,0 +1,306 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"sort"
+	"testing"
+)
+
+// setsEqualFor runs the same battery of assertions against both the
+// thread-safe and thread-unsafe constructors, since they're expected to
+// behave identically and most bugs in one are copy-paste bugs in both.
+func setsEqualFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1, 2, 3)
+		if s.Cardinality() != 3 {
+			t.Fatalf("Cardinality() = %d, want 3", s.Cardinality())
+		}
+		if !s.Contains(1, 2, 3) {
+			t.Fatal("Contains(1, 2, 3) = false")
+		}
+		if s.Contains(4) {
+			t.Fatal("Contains(4) = true")
+		}
+		if !s.Add(4) {
+			t.Fatal("Add(4) = false on first insert")
+		}
+		if s.Add(4) {
+			t.Fatal("Add(4) = true on duplicate insert")
+		}
+
+		s.Remove(4)
+		if s.Contains(4) {
+			t.Fatal("Contains(4) = true after Remove")
+		}
+
+		clone := s.Clone()
+		if !clone.Equal(s) {
+			t.Fatal("Clone() is not Equal to original")
+		}
+		clone.Add(99)
+		if s.Contains(99) {
+			t.Fatal("mutating Clone() mutated the original")
+		}
+
+		s.Clear()
+		if s.Cardinality() != 0 {
+			t.Fatalf("Cardinality() = %d after Clear, want 0", s.Cardinality())
+		}
+	})
+}
+
+func TestSetBasicOps(t *testing.T) {
+	setsEqualFor(t, "threadsafe", NewSet)
+	setsEqualFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func algebraFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		a := newSet(1, 2, 3, 4)
+		b := newSet(3, 4, 5, 6)
+
+		if !a.Union(b).Equal(newSet(1, 2, 3, 4, 5, 6)) {
+			t.Errorf("Union = %v, want {1,2,3,4,5,6}", a.Union(b))
+		}
+		if !a.Intersect(b).Equal(newSet(3, 4)) {
+			t.Errorf("Intersect = %v, want {3,4}", a.Intersect(b))
+		}
+		if !a.Difference(b).Equal(newSet(1, 2)) {
+			t.Errorf("Difference = %v, want {1,2}", a.Difference(b))
+		}
+		if !a.SymmetricDifference(b).Equal(newSet(1, 2, 5, 6)) {
+			t.Errorf("SymmetricDifference = %v, want {1,2,5,6}", a.SymmetricDifference(b))
+		}
+
+		if !newSet(1, 2).IsSubset(a) {
+			t.Error("{1,2}.IsSubset(a) = false")
+		}
+		if !newSet(1, 2).IsProperSubset(a) {
+			t.Error("{1,2}.IsProperSubset(a) = false")
+		}
+		if a.IsProperSubset(a) {
+			t.Error("a.IsProperSubset(a) = true")
+		}
+		if !a.IsSuperset(newSet(1, 2)) {
+			t.Error("a.IsSuperset({1,2}) = false")
+		}
+		if !a.IsProperSuperset(newSet(1, 2)) {
+			t.Error("a.IsProperSuperset({1,2}) = false")
+		}
+		if a.IsProperSuperset(a) {
+			t.Error("a.IsProperSuperset(a) = true")
+		}
+	})
+}
+
+func TestSetAlgebra(t *testing.T) {
+	algebraFor(t, "threadsafe", NewSet)
+	algebraFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func eachAndIterFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1, 2, 3)
+
+		var viaEach []int
+		s.Each(func(i interface{}) bool {
+			viaEach = append(viaEach, i.(int))
+			return false
+		})
+		sort.Ints(viaEach)
+		if len(viaEach) != 3 || viaEach[0] != 1 || viaEach[1] != 2 || viaEach[2] != 3 {
+			t.Fatalf("Each visited %v, want {1,2,3}", viaEach)
+		}
+
+		var viaIter []int
+		for i := range s.Iter() {
+			viaIter = append(viaIter, i.(int))
+		}
+		sort.Ints(viaIter)
+		if len(viaIter) != 3 || viaIter[0] != 1 || viaIter[1] != 2 || viaIter[2] != 3 {
+			t.Fatalf("Iter yielded %v, want {1,2,3}", viaIter)
+		}
+
+		it := s.Iterator()
+		<-it.C
+		it.Stop()
+
+		// it.C must eventually close once Stop is called, even if there
+		// was more to yield.
+		for range it.C {
+		}
+	})
+}
+
+func TestSetEachAndIter(t *testing.T) {
+	eachAndIterFor(t, "threadsafe", NewSet)
+	eachAndIterFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func popFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1)
+		elem := s.Pop()
+		if elem != 1 {
+			t.Fatalf("Pop() = %v, want 1", elem)
+		}
+		if elem := s.Pop(); elem != nil {
+			t.Fatalf("Pop() on an empty set = %v, want nil", elem)
+		}
+	})
+}
+
+func TestSetPop(t *testing.T) {
+	popFor(t, "threadsafe", NewSet)
+	popFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func toSliceFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1, 2, 3)
+		slice := make([]int, 0, len(s.ToSlice()))
+		for _, v := range s.ToSlice() {
+			slice = append(slice, v.(int))
+		}
+		sort.Ints(slice)
+		if len(slice) != 3 || slice[0] != 1 || slice[1] != 2 || slice[2] != 3 {
+			t.Fatalf("ToSlice() = %v, want [1 2 3]", slice)
+		}
+	})
+}
+
+func TestSetToSlice(t *testing.T) {
+	toSliceFor(t, "threadsafe", NewSet)
+	toSliceFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func stringFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1)
+		if s.String() != "Set{1}" {
+			t.Fatalf("String() = %q, want %q", s.String(), "Set{1}")
+		}
+	})
+}
+
+func TestSetString(t *testing.T) {
+	stringFor(t, "threadsafe", NewSet)
+	stringFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+// marshalUnmarshalFor round-trips through MarshalJSON/UnmarshalJSON.
+// Those decode through encoding/json, so numbers come back as float64
+// rather than int; compare against a set built the same way rather than
+// the original int-typed set.
+func marshalUnmarshalFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1, 2, 3)
+		b, err := s.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+
+		round := newSet()
+		if err := round.UnmarshalJSON(b); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		want := newSet(float64(1), float64(2), float64(3))
+		if !round.Equal(want) {
+			t.Fatalf("round-tripped set %v != want %v", round, want)
+		}
+	})
+}
+
+func TestSetMarshalUnmarshalJSON(t *testing.T) {
+	marshalUnmarshalFor(t, "threadsafe", NewSet)
+	marshalUnmarshalFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func powerSetFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		s := newSet(1, 2)
+		ps := s.PowerSet()
+
+		if ps.Cardinality() != 4 {
+			t.Fatalf("PowerSet cardinality = %d, want 4 (2^2)", ps.Cardinality())
+		}
+
+		var sawEmpty, sawFull bool
+		ps.Each(func(subset interface{}) bool {
+			sub := subset.(Set)
+			switch sub.Cardinality() {
+			case 0:
+				sawEmpty = true
+			case 2:
+				if sub.Contains(1, 2) {
+					sawFull = true
+				}
+			}
+			return false
+		})
+		if !sawEmpty {
+			t.Error("PowerSet did not contain the empty set")
+		}
+		if !sawFull {
+			t.Error("PowerSet did not contain the full set")
+		}
+	})
+}
+
+func TestPowerSet(t *testing.T) {
+	powerSetFor(t, "threadsafe", NewSet)
+	powerSetFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
+
+func cartesianProductFor(t *testing.T, name string, newSet func(...interface{}) Set) {
+	t.Run(name, func(t *testing.T) {
+		a := newSet(1, 2)
+		b := newSet("x", "y")
+
+		product := a.CartesianProduct(b)
+		if product.Cardinality() != 4 {
+			t.Fatalf("CartesianProduct cardinality = %d, want 4", product.Cardinality())
+		}
+
+		found := false
+		product.Each(func(i interface{}) bool {
+			pair := i.(OrderedPair)
+			if pair.Equal(OrderedPair{First: 1, Second: "x"}) {
+				found = true
+			}
+			return false
+		})
+		if !found {
+			t.Error("CartesianProduct missing pair {1, x}")
+		}
+	})
+}
+
+func TestCartesianProduct(t *testing.T) {
+	cartesianProductFor(t, "threadsafe", NewSet)
+	cartesianProductFor(t, "threadunsafe", NewThreadUnsafeSet)
+}
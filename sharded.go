@@ -0,0 +1,438 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sync"
+)
+
+// DefaultShardCount is the number of shards a set created with NewSet's
+// sharded counterpart uses when the caller doesn't specify one.
+const DefaultShardCount = 32
+
+// shard is a single partition of a shardedThreadSafeSet: an independently
+// lockable threadUnsafeSet.
+type shard struct {
+	sync.RWMutex
+	s threadUnsafeSet
+}
+
+// shardedThreadSafeSet is a Set implementation that spreads its elements
+// across a fixed number of independently-locked shards, rather than
+// funneling every operation through a single sync.RWMutex. This trades a
+// small amount of memory and hashing overhead for much better throughput
+// under concurrent Add/Contains from many goroutines, since unrelated
+// elements no longer contend on the same lock.
+type shardedThreadSafeSet struct {
+	shards []*shard
+}
+
+// NewShardedSet creates a new thread-safe Set whose elements are
+// partitioned across the given number of shards. Use this instead of the
+// default NewSet-backed implementation when many goroutines will be
+// calling Add/Contains at a high rate and the single RWMutex in the
+// default implementation becomes a bottleneck. shards must be positive;
+// non-positive values fall back to DefaultShardCount.
+func NewShardedSet(shards int) Set {
+	if shards <= 0 {
+		shards = DefaultShardCount
+	}
+
+	set := &shardedThreadSafeSet{shards: make([]*shard, shards)}
+	for i := range set.shards {
+		set.shards[i] = &shard{s: newThreadUnsafeSet()}
+	}
+	return set
+}
+
+func (set *shardedThreadSafeSet) shardFor(i interface{}) *shard {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%v", i)
+	return set.shards[h.Sum32()%uint32(len(set.shards))]
+}
+
+// lockAll acquires every shard's lock, in ascending shard-index order, so
+// that any two goroutines locking the same set's shards always do so in
+// the same order.
+func (set *shardedThreadSafeSet) lockAll() {
+	for _, sh := range set.shards {
+		sh.Lock()
+	}
+}
+
+func (set *shardedThreadSafeSet) unlockAll() {
+	for _, sh := range set.shards {
+		sh.Unlock()
+	}
+}
+
+func (set *shardedThreadSafeSet) rLockAll() {
+	for _, sh := range set.shards {
+		sh.RLock()
+	}
+}
+
+func (set *shardedThreadSafeSet) rUnlockAll() {
+	for _, sh := range set.shards {
+		sh.RUnlock()
+	}
+}
+
+// withOrderedLocks acquires read locks on both set and o, in a
+// deterministic global order derived from each set's address, so that a
+// concurrent set.Union(o) and o.Union(set) can never deadlock against
+// each other by locking in opposite orders.
+func withOrderedLocks(set, o *shardedThreadSafeSet, fn func()) {
+	first, second := set, o
+	if reflect.ValueOf(o).Pointer() < reflect.ValueOf(set).Pointer() {
+		first, second = o, set
+	}
+
+	first.rLockAll()
+	defer first.rUnlockAll()
+
+	if second != first {
+		second.rLockAll()
+		defer second.rUnlockAll()
+	}
+
+	fn()
+}
+
+func (set *shardedThreadSafeSet) Add(i interface{}) bool {
+	sh := set.shardFor(i)
+	sh.Lock()
+	defer sh.Unlock()
+	return sh.s.Add(i)
+}
+
+func (set *shardedThreadSafeSet) Contains(i ...interface{}) bool {
+	for _, elem := range i {
+		sh := set.shardFor(elem)
+		sh.RLock()
+		found := sh.s.Contains(elem)
+		sh.RUnlock()
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *shardedThreadSafeSet) Remove(i interface{}) {
+	sh := set.shardFor(i)
+	sh.Lock()
+	defer sh.Unlock()
+	delete(sh.s, i)
+}
+
+func (set *shardedThreadSafeSet) Clear() {
+	set.lockAll()
+	defer set.unlockAll()
+	for _, sh := range set.shards {
+		sh.s = newThreadUnsafeSet()
+	}
+}
+
+func (set *shardedThreadSafeSet) Cardinality() int {
+	set.rLockAll()
+	defer set.rUnlockAll()
+
+	total := 0
+	for _, sh := range set.shards {
+		total += len(sh.s)
+	}
+	return total
+}
+
+func (set *shardedThreadSafeSet) Each(cb func(interface{}) bool) {
+	set.rLockAll()
+	defer set.rUnlockAll()
+
+	for _, sh := range set.shards {
+		for elem := range sh.s {
+			if cb(elem) {
+				return
+			}
+		}
+	}
+}
+
+func (set *shardedThreadSafeSet) Iter() <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		set.rLockAll()
+		defer set.rUnlockAll()
+
+		for _, sh := range set.shards {
+			for elem := range sh.s {
+				ch <- elem
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (set *shardedThreadSafeSet) Iterator() *Iterator {
+	iterator, ch, stopCh := newIterator()
+
+	go func() {
+		set.rLockAll()
+	L:
+		for _, sh := range set.shards {
+			for elem := range sh.s {
+				select {
+				case <-stopCh:
+					break L
+				case ch <- elem:
+				}
+			}
+		}
+		close(ch)
+		set.rUnlockAll()
+	}()
+
+	return iterator
+}
+
+func (set *shardedThreadSafeSet) ToSlice() []interface{} {
+	set.rLockAll()
+	defer set.rUnlockAll()
+
+	total := 0
+	for _, sh := range set.shards {
+		total += len(sh.s)
+	}
+	keys := make([]interface{}, 0, total)
+	for _, sh := range set.shards {
+		for elem := range sh.s {
+			keys = append(keys, elem)
+		}
+	}
+	return keys
+}
+
+func (set *shardedThreadSafeSet) String() string {
+	set.rLockAll()
+	defer set.rUnlockAll()
+
+	flattened := newThreadUnsafeSet()
+	for _, sh := range set.shards {
+		for elem := range sh.s {
+			flattened.Add(elem)
+		}
+	}
+	return flattened.String()
+}
+
+func (set *shardedThreadSafeSet) Clone() Set {
+	clone := NewShardedSet(len(set.shards)).(*shardedThreadSafeSet)
+
+	set.rLockAll()
+	defer set.rUnlockAll()
+
+	for shIdx, sh := range set.shards {
+		for elem := range sh.s {
+			clone.shards[shIdx].s.Add(elem)
+		}
+	}
+	return clone
+}
+
+func (set *shardedThreadSafeSet) Pop() interface{} {
+	set.lockAll()
+	defer set.unlockAll()
+
+	for _, sh := range set.shards {
+		for elem := range sh.s {
+			delete(sh.s, elem)
+			return elem
+		}
+	}
+	return nil
+}
+
+func (set *shardedThreadSafeSet) IsSubset(other Set) bool {
+	isSubset := true
+	set.Each(func(elem interface{}) bool {
+		if !other.Contains(elem) {
+			isSubset = false
+			return true
+		}
+		return false
+	})
+	return isSubset
+}
+
+func (set *shardedThreadSafeSet) IsProperSubset(other Set) bool {
+	return set.IsSubset(other) && set.Cardinality() < other.Cardinality()
+}
+
+func (set *shardedThreadSafeSet) IsSuperset(other Set) bool {
+	return other.IsSubset(set)
+}
+
+func (set *shardedThreadSafeSet) IsProperSuperset(other Set) bool {
+	return other.IsProperSubset(set)
+}
+
+func (set *shardedThreadSafeSet) Equal(other Set) bool {
+	if set.Cardinality() != other.Cardinality() {
+		return false
+	}
+	return set.IsSubset(other)
+}
+
+// containsLocked reports whether i is a member of set, reading the shard's
+// map directly instead of calling Contains. The caller must already hold
+// at least a read lock on i's shard (e.g. via rLockAll/withOrderedLocks);
+// calling Contains instead would re-acquire that same shard's RWMutex for
+// read, which is not safe to do while already holding it; sync.RWMutex is
+// not re-entrant, and a writer queued in between the two RLock calls would
+// deadlock the whole thing.
+func (set *shardedThreadSafeSet) containsLocked(i interface{}) bool {
+	sh := set.shardFor(i)
+	_, found := sh.s[i]
+	return found
+}
+
+// combine computes a set-algebra op between set and other: it visits
+// every element reachable from either operand exactly once, tells keep
+// whether that element was a member of set and/or of other, and adds it
+// to the result when keep says to. Union, Intersect, Difference and
+// SymmetricDifference are all expressible this way, which means they all
+// get the same locking treatment: when other is itself a
+// shardedThreadSafeSet, both operands' shards are locked up front via
+// withOrderedLocks, in the same globally-consistent order regardless of
+// which side the call started from, so set.Op(other) and other.Op(set)
+// running concurrently can never deadlock against each other. While those
+// locks are held, membership is read via containsLocked rather than
+// Contains, since Contains would try to re-lock an already-locked shard.
+func (set *shardedThreadSafeSet) combine(other Set, keep func(inSet, inOther bool) bool) Set {
+	result := NewShardedSet(len(set.shards)).(*shardedThreadSafeSet)
+
+	add := func(elem interface{}, inSet, inOther bool) {
+		if keep(inSet, inOther) {
+			result.Add(elem)
+		}
+	}
+
+	if o, ok := other.(*shardedThreadSafeSet); ok {
+		withOrderedLocks(set, o, func() {
+			for _, sh := range set.shards {
+				for elem := range sh.s {
+					add(elem, true, o.containsLocked(elem))
+				}
+			}
+			for _, sh := range o.shards {
+				for elem := range sh.s {
+					if !set.containsLocked(elem) {
+						add(elem, false, true)
+					}
+				}
+			}
+		})
+		return result
+	}
+
+	set.Each(func(elem interface{}) bool {
+		add(elem, true, other.Contains(elem))
+		return false
+	})
+	other.Each(func(elem interface{}) bool {
+		if !set.Contains(elem) {
+			add(elem, false, true)
+		}
+		return false
+	})
+	return result
+}
+
+func (set *shardedThreadSafeSet) Union(other Set) Set {
+	return set.combine(other, func(inSet, inOther bool) bool {
+		return true
+	})
+}
+
+func (set *shardedThreadSafeSet) Intersect(other Set) Set {
+	return set.combine(other, func(inSet, inOther bool) bool {
+		return inSet && inOther
+	})
+}
+
+func (set *shardedThreadSafeSet) Difference(other Set) Set {
+	return set.combine(other, func(inSet, inOther bool) bool {
+		return inSet && !inOther
+	})
+}
+
+func (set *shardedThreadSafeSet) SymmetricDifference(other Set) Set {
+	return set.combine(other, func(inSet, inOther bool) bool {
+		return inSet != inOther
+	})
+}
+
+func (set *shardedThreadSafeSet) PowerSet() Set {
+	flattened := newThreadUnsafeSet()
+	set.Each(func(elem interface{}) bool {
+		flattened.Add(elem)
+		return false
+	})
+	return flattened.PowerSet()
+}
+
+func (set *shardedThreadSafeSet) CartesianProduct(other Set) Set {
+	flattened := newThreadUnsafeSet()
+	set.Each(func(elem interface{}) bool {
+		flattened.Add(elem)
+		return false
+	})
+	return flattened.CartesianProduct(other)
+}
+
+func (set *shardedThreadSafeSet) MarshalJSON() ([]byte, error) {
+	flattened := newThreadUnsafeSet()
+	set.Each(func(elem interface{}) bool {
+		flattened.Add(elem)
+		return false
+	})
+	return flattened.MarshalJSON()
+}
+
+func (set *shardedThreadSafeSet) UnmarshalJSON(p []byte) error {
+	flattened := newThreadUnsafeSet()
+	if err := flattened.UnmarshalJSON(p); err != nil {
+		return err
+	}
+	for elem := range flattened {
+		set.Add(elem)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedSetBasicOps(t *testing.T) {
+	s := NewShardedSet(4)
+	if !s.Add(1) {
+		t.Fatal("Add(1) = false on first insert")
+	}
+	if s.Add(1) {
+		t.Fatal("Add(1) = true on duplicate insert")
+	}
+	if !s.Contains(1) {
+		t.Fatal("Contains(1) = false after Add")
+	}
+	if s.Cardinality() != 1 {
+		t.Fatalf("Cardinality() = %d, want 1", s.Cardinality())
+	}
+	s.Remove(1)
+	if s.Contains(1) {
+		t.Fatal("Contains(1) = true after Remove")
+	}
+}
+
+func TestShardedSetAlgebraMatchesThreadSafeSet(t *testing.T) {
+	a := NewShardedSet(4)
+	b := NewShardedSet(4)
+	for i := 0; i < 10; i++ {
+		a.Add(i)
+	}
+	for i := 5; i < 15; i++ {
+		b.Add(i)
+	}
+
+	wantUnion := NewSet()
+	for i := 0; i < 15; i++ {
+		wantUnion.Add(i)
+	}
+	if !a.Union(b).Equal(wantUnion) {
+		t.Errorf("Union = %v, want %v", a.Union(b), wantUnion)
+	}
+
+	wantIntersect := NewSet(5, 6, 7, 8, 9)
+	if !a.Intersect(b).Equal(wantIntersect) {
+		t.Errorf("Intersect = %v, want %v", a.Intersect(b), wantIntersect)
+	}
+
+	wantDiff := NewSet(0, 1, 2, 3, 4)
+	if !a.Difference(b).Equal(wantDiff) {
+		t.Errorf("Difference = %v, want %v", a.Difference(b), wantDiff)
+	}
+
+	wantSymDiff := NewSet(0, 1, 2, 3, 4, 10, 11, 12, 13, 14)
+	if !a.SymmetricDifference(b).Equal(wantSymDiff) {
+		t.Errorf("SymmetricDifference = %v, want %v", a.SymmetricDifference(b), wantSymDiff)
+	}
+}
+
+// TestShardedSetCombineConcurrentWithMutationDoesNotDeadlock races
+// Intersect/Difference/SymmetricDifference/Union between two sharded sets
+// against goroutines that keep Add-ing and Remove-ing elements on both
+// sides. combine used to call back through Contains while already holding
+// every shard's read lock via withOrderedLocks, which re-acquired an
+// already-held sync.RWMutex for read and could deadlock once a writer
+// queued up in between; this pins that down with a bounded wait so a
+// regression fails this test after five seconds instead of hanging the
+// whole suite.
+func TestShardedSetCombineConcurrentWithMutationDoesNotDeadlock(t *testing.T) {
+	a := NewShardedSet(4)
+	b := NewShardedSet(4)
+	for i := 0; i < 50; i++ {
+		a.Add(i)
+		b.Add(i + 25)
+	}
+
+	stop := make(chan struct{})
+	var writers sync.WaitGroup
+	writers.Add(2)
+	go func() {
+		defer writers.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			a.Add(i % 50)
+			a.Remove(i % 50)
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			b.Add(i%50 + 25)
+			b.Remove(i%50 + 25)
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			a.Intersect(b)
+			a.Difference(b)
+			a.SymmetricDifference(b)
+			b.Union(a)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("combine deadlocked racing against concurrent Add/Remove")
+	}
+	close(stop)
+	writers.Wait()
+}
+
+// benchmarkConcurrentAddContains exercises the high-frequency Add/Contains
+// pattern called out in the sharded-set request (go-ethereum's account
+// cache and whisper known-message tracking), at goroutine counts from 1 up
+// to 64 so the single-mutex and sharded implementations can be compared
+// under realistic and heavy contention.
+func benchmarkConcurrentAddContains(b *testing.B, set Set, goroutines int) {
+	b.ResetTimer()
+
+	var wg sync.WaitGroup
+	perGoroutine := b.N / goroutines
+	if perGoroutine < 1 {
+		perGoroutine = 1
+	}
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				v := g*perGoroutine + i
+				set.Add(v)
+				set.Contains(v)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkRWMutexSet(b *testing.B) {
+	for _, goroutines := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("goroutines=%d", goroutines), func(b *testing.B) {
+			benchmarkConcurrentAddContains(b, NewSet(), goroutines)
+		})
+	}
+}
+
+func BenchmarkShardedSet(b *testing.B) {
+	for _, goroutines := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("goroutines=%d", goroutines), func(b *testing.B) {
+			benchmarkConcurrentAddContains(b, NewShardedSet(DefaultShardCount), goroutines)
+		})
+	}
+}
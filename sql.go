@@ -0,0 +1,100 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements driver.Valuer so a Set can be written to a database/sql
+// column, e.g. via a GORM model field. Elements are encoded with
+// DefaultCodec.
+func (set *threadSafeSet) Value() (driver.Value, error) {
+	set.RLock()
+	defer set.RUnlock()
+	return set.s.Value()
+}
+
+// Scan implements sql.Scanner so a Set can be populated from a
+// database/sql column previously written with Value.
+func (set *threadSafeSet) Scan(src interface{}) error {
+	set.Lock()
+	defer set.Unlock()
+	return set.s.Scan(src)
+}
+
+// DataType reports the SQL column type DefaultCodec expects to read from
+// and write to, e.g. for use from a GORM Migrator when generating a
+// schema for a struct field of this type.
+func (set *threadSafeSet) DataType() string {
+	return DefaultCodec.SQLType()
+}
+
+// Value implements driver.Valuer so a Set can be written to a database/sql
+// column, e.g. via a GORM model field. Elements are encoded with
+// DefaultCodec.
+func (set *threadUnsafeSet) Value() (driver.Value, error) {
+	encoded, err := DefaultCodec.Encode(set.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+// Scan implements sql.Scanner so a Set can be populated from a
+// database/sql column previously written with Value.
+func (set *threadUnsafeSet) Scan(src interface{}) error {
+	var data string
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		return fmt.Errorf("mapset: unsupported Scan source type %T", src)
+	}
+
+	items, err := DefaultCodec.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	*set = newThreadUnsafeSet()
+	for _, item := range items {
+		set.Add(item)
+	}
+	return nil
+}
+
+// DataType reports the SQL column type DefaultCodec expects to read from
+// and write to, e.g. for use from a GORM Migrator when generating a
+// schema for a struct field of this type.
+func (set *threadUnsafeSet) DataType() string {
+	return DefaultCodec.SQLType()
+}
@@ -0,0 +1,202 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"testing"
+)
+
+// SCOPE NOTE: this package has zero database driver dependencies, and
+// pulling one in (even a pure-Go sqlite driver, even gated behind a
+// build tag) would still make it a direct module requirement of
+// github.com/deckarep/golang-set, dragging its whole transitive closure
+// into every consumer's go.sum. So these tests round-trip through the
+// driver.Valuer/sql.Scanner methods directly, which covers the part
+// that's actually ours to get right — Value's encoding and Scan's
+// decoding agreeing with each other for every codec — without a real
+// database/sql connection in the loop.
+
+func TestJSONCodecRoundTripsPrimitiveTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		elems []interface{}
+	}{
+		{"strings", []interface{}{"a", "b", "c"}},
+		{"numbers", []interface{}{float64(1), float64(2), float64(3)}},
+		{"empty", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set := newThreadUnsafeSet()
+			for _, e := range tc.elems {
+				set.Add(e)
+			}
+
+			value, err := set.Value()
+			if err != nil {
+				t.Fatalf("Value() error = %v", err)
+			}
+
+			var restored threadUnsafeSet = newThreadUnsafeSet()
+			if err := restored.Scan(value); err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			if !restored.Equal(&set) {
+				t.Fatalf("round trip mismatch: got %v, want %v", restored.ToSlice(), set.ToSlice())
+			}
+		})
+	}
+}
+
+// TestJSONCodecDoesNotPreserveIntType documents, rather than hides, the
+// limitation called out on JSONCodec: a Set built from real ints does
+// not come back from Scan as ints, because encoding/json decodes every
+// JSON number into float64. This is the exact GORM int-field scenario
+// the Scan/Value support was added for, so it's worth a dedicated test
+// instead of the "numbers" case above, which only ever seeds float64
+// and would never catch this.
+func TestJSONCodecDoesNotPreserveIntType(t *testing.T) {
+	orig := newThreadUnsafeSet()
+	orig.Add(1)
+	orig.Add(2)
+	orig.Add(3)
+
+	value, err := orig.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	restored := newThreadUnsafeSet()
+	if err := restored.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if restored.Equal(&orig) {
+		t.Fatal("Equal() = true after round tripping ints through JSONCodec, want false (ints come back as float64)")
+	}
+
+	want := newThreadUnsafeSet()
+	want.Add(float64(1))
+	want.Add(float64(2))
+	want.Add(float64(3))
+	if !restored.Equal(&want) {
+		t.Fatalf("round-tripped set = %v, want float64 elements %v", restored.ToSlice(), want.ToSlice())
+	}
+}
+
+func TestCSVCodecRoundTripsStrings(t *testing.T) {
+	prev := DefaultCodec
+	DefaultCodec = CSVCodec{}
+	defer func() { DefaultCodec = prev }()
+
+	set := newThreadUnsafeSet()
+	set.Add("a")
+	set.Add("b")
+	set.Add("c")
+
+	value, err := set.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	restored := newThreadUnsafeSet()
+	if err := restored.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if !restored.Equal(&set) {
+		t.Fatalf("round trip mismatch: got %v, want %v", restored.ToSlice(), set.ToSlice())
+	}
+}
+
+// TestCSVCodecDropsSoleEmptyStringElement documents the caveat called
+// out on CSVCodec: it can't tell an empty set apart from a set
+// containing exactly one "" element, since both encode to "".
+func TestCSVCodecDropsSoleEmptyStringElement(t *testing.T) {
+	c := CSVCodec{}
+
+	encoded, err := c.Encode([]interface{}{""})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if encoded != "" {
+		t.Fatalf("Encode([\"\"]) = %q, want \"\"", encoded)
+	}
+
+	decoded, err := c.Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(decoded) != 0 {
+		t.Fatalf("Decode(%q) = %v, want no elements (this is the documented caveat)", encoded, decoded)
+	}
+}
+
+func TestThreadSafeSetScanValueRoundTrip(t *testing.T) {
+	prev := DefaultCodec
+	DefaultCodec = JSONCodec{}
+	defer func() { DefaultCodec = prev }()
+
+	orig := NewSet("x", "y", "z").(*threadSafeSet)
+
+	value, err := orig.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	restored := NewSet().(*threadSafeSet)
+	if err := restored.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if !restored.Equal(orig) {
+		t.Fatalf("round trip mismatch: got %v, want %v", restored.ToSlice(), orig.ToSlice())
+	}
+}
+
+func TestDataTypeMatchesCodec(t *testing.T) {
+	prev := DefaultCodec
+	defer func() { DefaultCodec = prev }()
+
+	DefaultCodec = JSONCodec{}
+	if got := NewSet().(*threadSafeSet).DataType(); got != "JSON" {
+		t.Errorf("DataType() with JSONCodec = %q, want JSON", got)
+	}
+
+	DefaultCodec = CSVCodec{}
+	if got := NewSet().(*threadSafeSet).DataType(); got != "TEXT" {
+		t.Errorf("DataType() with CSVCodec = %q, want TEXT", got)
+	}
+}
+
+func TestScanRejectsUnsupportedSourceType(t *testing.T) {
+	set := newThreadUnsafeSet()
+	if err := set.Scan(42); err == nil {
+		t.Error("Scan(42) error = nil, want error for unsupported source type")
+	}
+}
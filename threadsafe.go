@@ -262,7 +262,7 @@ func (set *threadSafeSet) MarshalJSON() ([]byte, error) {
 }
 
 func (set *threadSafeSet) UnmarshalJSON(p []byte) error {
-	set.RLock()
-	defer set.RUnlock()
+	set.Lock()
+	defer set.Unlock()
 	return set.s.UnmarshalJSON(p)
 }
@@ -0,0 +1,281 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type threadUnsafeSet map[interface{}]struct{}
+
+func newThreadUnsafeSet() threadUnsafeSet {
+	return make(threadUnsafeSet)
+}
+
+func (s *threadUnsafeSet) Add(i interface{}) bool {
+	_, found := (*s)[i]
+	if found {
+		return false
+	}
+	(*s)[i] = struct{}{}
+	return true
+}
+
+func (s *threadUnsafeSet) Contains(i ...interface{}) bool {
+	for _, val := range i {
+		if _, ok := (*s)[val]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet) IsSubset(other Set) bool {
+	o := other.(*threadUnsafeSet)
+	for elem := range *s {
+		if !o.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet) IsProperSubset(other Set) bool {
+	return s.IsSubset(other) && s.Cardinality() < other.Cardinality()
+}
+
+func (s *threadUnsafeSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+func (s *threadUnsafeSet) IsProperSuperset(other Set) bool {
+	return other.IsProperSubset(s)
+}
+
+func (s *threadUnsafeSet) Union(other Set) Set {
+	o := other.(*threadUnsafeSet)
+
+	unionedSet := newThreadUnsafeSet()
+	for elem := range *s {
+		unionedSet.Add(elem)
+	}
+	for elem := range *o {
+		unionedSet.Add(elem)
+	}
+	return &unionedSet
+}
+
+func (s *threadUnsafeSet) Intersect(other Set) Set {
+	o := other.(*threadUnsafeSet)
+
+	intersection := newThreadUnsafeSet()
+	smaller, larger := s, o
+	if o.Cardinality() < s.Cardinality() {
+		smaller, larger = o, s
+	}
+	for elem := range *smaller {
+		if larger.Contains(elem) {
+			intersection.Add(elem)
+		}
+	}
+	return &intersection
+}
+
+func (s *threadUnsafeSet) Difference(other Set) Set {
+	o := other.(*threadUnsafeSet)
+
+	difference := newThreadUnsafeSet()
+	for elem := range *s {
+		if !o.Contains(elem) {
+			difference.Add(elem)
+		}
+	}
+	return &difference
+}
+
+func (s *threadUnsafeSet) SymmetricDifference(other Set) Set {
+	o := other.(*threadUnsafeSet)
+
+	aDiff := s.Difference(o).(*threadUnsafeSet)
+	bDiff := o.Difference(s).(*threadUnsafeSet)
+	return aDiff.Union(bDiff)
+}
+
+func (s *threadUnsafeSet) Clear() {
+	*s = newThreadUnsafeSet()
+}
+
+func (s *threadUnsafeSet) Remove(i interface{}) {
+	delete(*s, i)
+}
+
+func (s *threadUnsafeSet) Cardinality() int {
+	return len(*s)
+}
+
+func (s *threadUnsafeSet) Each(cb func(interface{}) bool) {
+	for elem := range *s {
+		if cb(elem) {
+			break
+		}
+	}
+}
+
+func (s *threadUnsafeSet) Iter() <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		for elem := range *s {
+			ch <- elem
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (s *threadUnsafeSet) Iterator() *Iterator {
+	iterator, ch, stopCh := newIterator()
+
+	go func() {
+	L:
+		for elem := range *s {
+			select {
+			case <-stopCh:
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+	}()
+
+	return iterator
+}
+
+func (s *threadUnsafeSet) Equal(other Set) bool {
+	o := other.(*threadUnsafeSet)
+
+	if s.Cardinality() != o.Cardinality() {
+		return false
+	}
+	for elem := range *s {
+		if !o.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet) Clone() Set {
+	clonedSet := newThreadUnsafeSet()
+	for elem := range *s {
+		clonedSet.Add(elem)
+	}
+	return &clonedSet
+}
+
+func (s *threadUnsafeSet) String() string {
+	items := make([]string, 0, len(*s))
+	for elem := range *s {
+		items = append(items, fmt.Sprintf("%v", elem))
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+func (s *threadUnsafeSet) Pop() interface{} {
+	for elem := range *s {
+		delete(*s, elem)
+		return elem
+	}
+	return nil
+}
+
+func (s *threadUnsafeSet) PowerSet() Set {
+	elements := s.ToSlice()
+	powerSet := newThreadUnsafeSet()
+	empty := newThreadUnsafeSet()
+	powerSet.Add(&empty)
+
+	for _, elem := range elements {
+		existing := powerSet.ToSlice()
+		for _, sub := range existing {
+			subset := sub.(*threadUnsafeSet)
+			withElem := subset.Clone().(*threadUnsafeSet)
+			withElem.Add(elem)
+			powerSet.Add(withElem)
+		}
+	}
+	return &powerSet
+}
+
+func (s *threadUnsafeSet) CartesianProduct(other Set) Set {
+	o := other.(*threadUnsafeSet)
+
+	cartProduct := newThreadUnsafeSet()
+	for elem := range *s {
+		for otherElem := range *o {
+			cartProduct.Add(OrderedPair{First: elem, Second: otherElem})
+		}
+	}
+	return &cartProduct
+}
+
+func (s *threadUnsafeSet) ToSlice() []interface{} {
+	keys := make([]interface{}, 0, s.Cardinality())
+	for elem := range *s {
+		keys = append(keys, elem)
+	}
+	return keys
+}
+
+func (s *threadUnsafeSet) MarshalJSON() ([]byte, error) {
+	items := make([]string, 0, s.Cardinality())
+
+	for elem := range *s {
+		b, err := json.Marshal(elem)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, string(b))
+	}
+
+	return []byte(fmt.Sprintf("[%s]", strings.Join(items, ","))), nil
+}
+
+func (s *threadUnsafeSet) UnmarshalJSON(b []byte) error {
+	var i []interface{}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	if err := d.Decode(&i); err != nil {
+		return err
+	}
+
+	for _, v := range i {
+		s.Add(v)
+	}
+	return nil
+}